@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserAddresses 查询用户的收货地址
+// GET /users/:id/addresses
+func (s *Server) GetUserAddresses(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return
+	}
+	if !canAccessUser(currentUser(c), uint(userID)) {
+		respondForbidden(c)
+		return
+	}
+
+	addresses, err := s.addresses.ListByUser(c.Request.Context(), uint(userID))
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    addresses,
+	})
+}
+
+// CreateAddress 创建收货地址
+// POST /users/:id/addresses
+func (s *Server) CreateAddress(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return
+	}
+	if !canManageAddresses(currentUser(c), uint(userID)) {
+		respondForbidden(c)
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	address, err := s.addresses.Create(c.Request.Context(), uint(userID), req)
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "创建成功",
+		Data:    address,
+	})
+}
+
+// UpdateAddress 修改收货地址
+// PUT /users/:id/addresses/:aid
+func (s *Server) UpdateAddress(c *gin.Context) {
+	userID, addressID, ok := parseAddressPath(c)
+	if !ok {
+		return
+	}
+
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	address, err := s.addresses.Update(c.Request.Context(), userID, addressID, req)
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    address,
+	})
+}
+
+// SetDefaultAddress 设为默认地址
+// POST /users/:id/addresses/:aid/default
+func (s *Server) SetDefaultAddress(c *gin.Context) {
+	userID, addressID, ok := parseAddressPath(c)
+	if !ok {
+		return
+	}
+
+	address, err := s.addresses.SetDefault(c.Request.Context(), userID, addressID)
+	if err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "已设为默认地址",
+		Data:    address,
+	})
+}
+
+// DeleteAddress 删除收货地址
+// DELETE /users/:id/addresses/:aid
+func (s *Server) DeleteAddress(c *gin.Context) {
+	userID, addressID, ok := parseAddressPath(c)
+	if !ok {
+		return
+	}
+
+	if err := s.addresses.Delete(c.Request.Context(), userID, addressID); err != nil {
+		respondAddressError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "删除成功",
+	})
+}
+
+// parseAddressPath 解析路径中的用户ID和地址ID，并校验当前用户是否可以修改该用户的地址
+// 校验失败时已经写入响应，调用方直接返回即可
+func parseAddressPath(c *gin.Context) (userID, addressID uint, ok bool) {
+	uid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return 0, 0, false
+	}
+	aid, err := strconv.ParseUint(c.Param("aid"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的地址ID",
+		})
+		return 0, 0, false
+	}
+	if !canManageAddresses(currentUser(c), uint(uid)) {
+		respondForbidden(c)
+		return 0, 0, false
+	}
+	return uint(uid), uint(aid), true
+}
+
+// respondAddressError 将收货地址业务错误映射为 HTTP 响应
+func respondAddressError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrUserNotFound),
+		errors.Is(err, ErrAddressNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrAddressInUse):
+		status = http.StatusConflict
+	}
+	c.JSON(status, Response{
+		Code:    status,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func newAddressRequest(district string, isDefault bool) AddressRequest {
+	return AddressRequest{
+		ReceiverName:  "王五",
+		ReceiverPhone: "13800138003",
+		Province:      "广东省",
+		City:          "深圳市",
+		District:      district,
+		Detail:        "科技园南区1栋",
+		PostalCode:    "518057",
+		IsDefault:     isDefault,
+	}
+}
+
+// defaultAddressID 返回用户当前的默认地址ID，没有默认地址时返回 0
+func defaultAddressID(t *testing.T, ts *testServer, userID uint) uint {
+	t.Helper()
+	var addresses []Address
+	if err := ts.db.Where("user_id = ? AND is_default = ?", userID, true).Find(&addresses).Error; err != nil {
+		t.Fatalf("查询默认地址失败: %v", err)
+	}
+	if len(addresses) > 1 {
+		t.Fatalf("用户 %d 有 %d 个默认地址", userID, len(addresses))
+	}
+	if len(addresses) == 0 {
+		return 0
+	}
+	return addresses[0].ID
+}
+
+func TestGetUserAddresses(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/users/1/addresses", ts.login("zhangsan"), nil, http.StatusOK)
+	var addresses []Address
+	resp.decode(t, &addresses)
+	// 默认地址排在最前
+	if len(addresses) != 2 || addresses[0].ID != 1 || !addresses[0].IsDefault {
+		t.Errorf("张三的地址 = %+v", addresses)
+	}
+
+	// 运营可以查看地址用于发货
+	ts.expect(http.MethodGet, "/users/1/addresses", ts.login("operator"), nil, http.StatusOK)
+	ts.expect(http.MethodGet, "/users/1/addresses", ts.login("lisi"), nil, http.StatusForbidden)
+}
+
+func TestCreateAddress(t *testing.T) {
+	ts := newTestServer(t)
+	wangwu := ts.login("wangwu")
+
+	// 第一个地址自动成为默认地址
+	resp := ts.expect(http.MethodPost, "/users/3/addresses", wangwu, newAddressRequest("南山区", false), http.StatusCreated)
+	var first Address
+	resp.decode(t, &first)
+	if first.UserID != fixtureWangwuID || !first.IsDefault {
+		t.Errorf("第一个地址 = %+v", first)
+	}
+
+	resp = ts.expect(http.MethodPost, "/users/3/addresses", wangwu, newAddressRequest("福田区", true), http.StatusCreated)
+	var second Address
+	resp.decode(t, &second)
+	if got := defaultAddressID(t, ts, fixtureWangwuID); got != second.ID {
+		t.Errorf("默认地址 = %d，期望 %d", got, second.ID)
+	}
+
+	ts.expect(http.MethodPost, "/users/3/addresses", wangwu, AddressRequest{ReceiverName: "王五"}, http.StatusBadRequest)
+	// 运营不能修改用户的地址，管理员可以
+	ts.expect(http.MethodPost, "/users/3/addresses", ts.login("operator"), newAddressRequest("南山区", false), http.StatusForbidden)
+	ts.expect(http.MethodPost, "/users/3/addresses", ts.login("zhangsan"), newAddressRequest("南山区", false), http.StatusForbidden)
+	ts.expect(http.MethodPost, "/users/3/addresses", ts.login("admin"), newAddressRequest("宝安区", false), http.StatusCreated)
+	ts.expect(http.MethodPost, "/users/999/addresses", ts.login("admin"), newAddressRequest("宝安区", false), http.StatusNotFound)
+}
+
+func TestUpdateAddress(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	resp := ts.expect(http.MethodPut, "/users/1/addresses/2", zhangsan, newAddressRequest("西城区", true), http.StatusOK)
+	var address Address
+	resp.decode(t, &address)
+	if address.District != "西城区" || address.ReceiverName != "王五" {
+		t.Errorf("修改后的地址 = %+v", address)
+	}
+	if got := defaultAddressID(t, ts, fixtureZhangsanID); got != 2 {
+		t.Errorf("默认地址 = %d，期望 2", got)
+	}
+
+	// 修改地址不影响已有订单的收货地址快照
+	var order Order
+	ts.db.First(&order, fixturePaidOrderID)
+	if order.District != "海淀区" {
+		t.Errorf("订单收货地址快照被修改: %q", order.District)
+	}
+
+	// is_default 为 false 或不传时保持原样，默认地址不会被取消
+	resp = ts.expect(http.MethodPut, "/users/1/addresses/2", zhangsan, newAddressRequest("东城区", false), http.StatusOK)
+	resp.decode(t, &address)
+	if !address.IsDefault || address.District != "东城区" {
+		t.Errorf("修改默认地址后 = %+v", address)
+	}
+	body := map[string]string{
+		"receiver_name": "张三", "receiver_phone": "13800138001",
+		"province": "北京市", "city": "北京市", "district": "朝阳区", "detail": "建国路1号",
+	}
+	ts.expect(http.MethodPut, "/users/1/addresses/2", zhangsan, body, http.StatusOK)
+	if got := defaultAddressID(t, ts, fixtureZhangsanID); got != 2 {
+		t.Errorf("默认地址 = %d，期望 2", got)
+	}
+	resp = ts.expect(http.MethodPut, "/users/1/addresses/1", zhangsan, newAddressRequest("海淀区", false), http.StatusOK)
+	resp.decode(t, &address)
+	if address.IsDefault {
+		t.Errorf("非默认地址被设为默认: %+v", address)
+	}
+
+	// 不能修改其他用户的地址
+	ts.expect(http.MethodPut, "/users/1/addresses/3", zhangsan, newAddressRequest("西城区", false), http.StatusNotFound)
+	ts.expect(http.MethodPut, "/users/1/addresses/2", zhangsan, AddressRequest{}, http.StatusBadRequest)
+	ts.expect(http.MethodPut, "/users/1/addresses/2", ts.login("lisi"), newAddressRequest("西城区", false), http.StatusForbidden)
+}
+
+func TestSetDefaultAddress(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	resp := ts.expect(http.MethodPost, "/users/1/addresses/2/default", zhangsan, nil, http.StatusOK)
+	var address Address
+	resp.decode(t, &address)
+	if address.ID != 2 || !address.IsDefault {
+		t.Errorf("默认地址 = %+v", address)
+	}
+	if got := defaultAddressID(t, ts, fixtureZhangsanID); got != 2 {
+		t.Errorf("默认地址 = %d，期望 2", got)
+	}
+
+	ts.expect(http.MethodPost, "/users/1/addresses/3/default", zhangsan, nil, http.StatusNotFound)
+	ts.expect(http.MethodPost, "/users/2/addresses/3/default", zhangsan, nil, http.StatusForbidden)
+	ts.expect(http.MethodPost, "/users/999/addresses/1/default", ts.login("admin"), nil, http.StatusNotFound)
+}
+
+// TestConcurrentSetDefaultAddress 并发设置不同的默认地址，最终只有一个默认地址
+func TestConcurrentSetDefaultAddress(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(addressID int) {
+			defer wg.Done()
+			resp := ts.do(http.MethodPost, fmt.Sprintf("/users/1/addresses/%d/default", addressID), zhangsan, nil)
+			if resp.Code != http.StatusOK {
+				t.Errorf("设置默认地址 %d: %d %s", addressID, resp.Code, resp.Message)
+			}
+		}(i%2 + 1)
+	}
+	wg.Wait()
+
+	if got := defaultAddressID(t, ts, fixtureZhangsanID); got != 1 && got != 2 {
+		t.Errorf("默认地址 = %d，期望 1 或 2", got)
+	}
+}
+
+// TestDefaultAddressUniqueIndex 绕过应用层直接写入第二个默认地址，由部分唯一索引拒绝；已删除的地址不受限制
+func TestDefaultAddressUniqueIndex(t *testing.T) {
+	ts := newTestServer(t)
+
+	if err := ts.db.Model(&Address{}).Where("id = ?", 2).Update("is_default", true).Error; err == nil {
+		t.Error("同一用户的第二个默认地址应被唯一索引拒绝")
+	}
+
+	if err := ts.db.Delete(&Address{}, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.db.Model(&Address{}).Where("id = ?", 2).Update("is_default", true).Error; err != nil {
+		t.Errorf("原默认地址删除后设置新的默认地址失败: %v", err)
+	}
+}
+
+func TestDeleteAddress(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	// 地址 1 被未完成的订单使用
+	resp := ts.expect(http.MethodDelete, "/users/1/addresses/1", zhangsan, nil, http.StatusConflict)
+	if resp.Message != ErrAddressInUse.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+
+	// 订单完成后可以删除，默认地址转移到剩余的地址
+	ts.db.Model(&Order{}).Where("address_id = ?", 1).Update("status", OrderStatusCompleted)
+	ts.expect(http.MethodDelete, "/users/1/addresses/1", zhangsan, nil, http.StatusOK)
+	if got := defaultAddressID(t, ts, fixtureZhangsanID); got != 2 {
+		t.Errorf("删除默认地址后默认地址 = %d，期望 2", got)
+	}
+
+	ts.expect(http.MethodDelete, "/users/1/addresses/1", zhangsan, nil, http.StatusNotFound)
+	ts.expect(http.MethodDelete, "/users/2/addresses/3", zhangsan, nil, http.StatusForbidden)
+}
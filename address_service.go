@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAddressInUse 地址被未完成的订单引用
+var ErrAddressInUse = errors.New("收货地址被未完成的订单使用，不能删除")
+
+// unfinishedOrderStatuses 未完成的订单状态，这些订单的收货地址不能删除
+var unfinishedOrderStatuses = []int8{OrderStatusPending, OrderStatusPaid, OrderStatusShipped}
+
+// AddressRequest 创建/修改收货地址请求参数
+type AddressRequest struct {
+	ReceiverName  string `json:"receiver_name" binding:"required,max=50"`
+	ReceiverPhone string `json:"receiver_phone" binding:"required,max=20"`
+	Province      string `json:"province" binding:"required,max=50"`
+	City          string `json:"city" binding:"required,max=50"`
+	District      string `json:"district" binding:"required,max=50"`
+	Detail        string `json:"detail" binding:"required,max=255"`
+	PostalCode    string `json:"postal_code" binding:"max=10"`
+	IsDefault     bool   `json:"is_default"` // 修改地址时为 false 表示保持原样，不能通过修改取消默认地址
+}
+
+// listAddresses 查询用户的收货地址，默认地址排在最前
+func listAddresses(db *gorm.DB, userID uint) ([]Address, error) {
+	if err := ensureUserExists(db, userID); err != nil {
+		return nil, err
+	}
+
+	var addresses []Address
+	if err := db.Where("user_id = ?", userID).
+		Order("is_default DESC, id ASC").
+		Find(&addresses).Error; err != nil {
+		return nil, fmt.Errorf("查询收货地址失败: %v", err)
+	}
+	return addresses, nil
+}
+
+// createAddress 创建收货地址
+// 用户的第一个地址自动成为默认地址；设为默认时在同一事务中先取消原默认地址
+func createAddress(db *gorm.DB, userID uint, req AddressRequest) (*Address, error) {
+	address := Address{
+		UserID:        userID,
+		ReceiverName:  req.ReceiverName,
+		ReceiverPhone: req.ReceiverPhone,
+		Province:      req.Province,
+		City:          req.City,
+		District:      req.District,
+		Detail:        req.Detail,
+		PostalCode:    req.PostalCode,
+		IsDefault:     req.IsDefault,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserAddresses(tx, userID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&Address{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询收货地址失败: %v", err)
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearOtherDefaultAddresses(tx, userID, 0); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&address).Error; err != nil {
+			return fmt.Errorf("创建收货地址失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// updateAddress 修改收货地址
+// is_default 为 true 时设为默认地址并取消原默认地址；为 false 时保持原样，
+// 否则修改默认地址会让用户没有默认地址，更换默认地址应当把其他地址设为默认
+func updateAddress(db *gorm.DB, userID, addressID uint, req AddressRequest) (*Address, error) {
+	var address Address
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserAddresses(tx, userID); err != nil {
+			return err
+		}
+		if err := findUserAddress(tx, userID, addressID, &address); err != nil {
+			return err
+		}
+
+		address.ReceiverName = req.ReceiverName
+		address.ReceiverPhone = req.ReceiverPhone
+		address.Province = req.Province
+		address.City = req.City
+		address.District = req.District
+		address.Detail = req.Detail
+		address.PostalCode = req.PostalCode
+		if req.IsDefault {
+			address.IsDefault = true
+			if err := clearOtherDefaultAddresses(tx, userID, address.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&address).
+			Select("receiver_name", "receiver_phone", "province", "city", "district", "detail", "postal_code", "is_default").
+			Updates(&address).Error; err != nil {
+			return fmt.Errorf("修改收货地址失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// setDefaultAddress 设为默认地址，在同一事务中取消原默认地址
+func setDefaultAddress(db *gorm.DB, userID, addressID uint) (*Address, error) {
+	var address Address
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserAddresses(tx, userID); err != nil {
+			return err
+		}
+		if err := findUserAddress(tx, userID, addressID, &address); err != nil {
+			return err
+		}
+		if err := clearOtherDefaultAddresses(tx, userID, address.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&address).Update("is_default", true).Error; err != nil {
+			return fmt.Errorf("设置默认地址失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// deleteAddress 删除收货地址（软删除，已完成订单仍然可以读取原地址）
+// 地址被未完成的订单引用时拒绝删除；删除默认地址后，最近创建的地址成为新的默认地址
+func deleteAddress(db *gorm.DB, userID, addressID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserAddresses(tx, userID); err != nil {
+			return err
+		}
+		var address Address
+		if err := findUserAddress(tx, userID, addressID, &address); err != nil {
+			return err
+		}
+
+		var orderCount int64
+		if err := tx.Model(&Order{}).
+			Where("address_id = ? AND status IN ?", address.ID, unfinishedOrderStatuses).
+			Count(&orderCount).Error; err != nil {
+			return fmt.Errorf("查询订单失败: %v", err)
+		}
+		if orderCount > 0 {
+			return ErrAddressInUse
+		}
+
+		if err := tx.Delete(&address).Error; err != nil {
+			return fmt.Errorf("删除收货地址失败: %v", err)
+		}
+
+		if address.IsDefault {
+			var next Address
+			err := tx.Where("user_id = ?", userID).Order("id DESC").First(&next).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("查询收货地址失败: %v", err)
+			}
+			if err := tx.Model(&next).Update("is_default", true).Error; err != nil {
+				return fmt.Errorf("设置默认地址失败: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ensureUserExists 校验用户存在
+func ensureUserExists(db *gorm.DB, userID uint) error {
+	var count int64
+	if err := db.Model(&User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询用户失败: %v", err)
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// lockUserAddresses 锁定用户行，串行化同一用户修改默认地址的事务，用户不存在时返回 ErrUserNotFound
+// 只在应用层先取消再设置默认地址时，两个并发事务在读已提交隔离级别下看不到对方未提交的修改，
+// 会各自保留一个默认地址；PostgreSQL 和 SQLite 另有部分唯一索引 idx_addresses_user_default 兜底
+// SQLite 不支持 FOR UPDATE，写事务本身是串行的
+func lockUserAddresses(tx *gorm.DB, userID uint) error {
+	var user User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("锁定用户失败: %v", err)
+	}
+	return nil
+}
+
+// findUserAddress 查询属于指定用户的收货地址，不属于该用户时同样视为不存在
+func findUserAddress(db *gorm.DB, userID, addressID uint, address *Address) error {
+	if err := db.Where("id = ? AND user_id = ?", addressID, userID).First(address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAddressNotFound
+		}
+		return fmt.Errorf("查询收货地址失败: %v", err)
+	}
+	return nil
+}
+
+// clearOtherDefaultAddresses 取消用户除 keepID 以外的默认地址，保证每个用户最多一个默认地址
+func clearOtherDefaultAddresses(tx *gorm.DB, userID, keepID uint) error {
+	if err := tx.Model(&Address{}).
+		Where("user_id = ? AND id <> ? AND is_default = ?", userID, keepID, true).
+		Update("is_default", false).Error; err != nil {
+		return fmt.Errorf("取消原默认地址失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	Secret     []byte        // JWT 签名密钥（HS256）
+	AccessTTL  time.Duration // 访问令牌有效期
+	RefreshTTL time.Duration // 刷新令牌有效期
+}
+
+// 令牌类型，防止刷新令牌被当作访问令牌使用
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// currentUserKey 认证中间件在 gin.Context 中保存当前用户的键
+const currentUserKey = "currentUser"
+
+// 认证相关错误
+var (
+	ErrTokenInvalid = errors.New("无效的令牌")
+	ErrTokenExpired = errors.New("令牌已过期")
+)
+
+// TokenClaims JWT 载荷
+type TokenClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// newAuthConfig 根据认证配置创建 AuthConfig
+// 未设置 JWT 密钥时生成随机密钥（重启后已签发的令牌失效），release 模式下 loadConfig 已拒绝空密钥
+func newAuthConfig(settings AuthSettings) (AuthConfig, error) {
+	config := AuthConfig{
+		Secret:     []byte(settings.JWTSecret),
+		AccessTTL:  settings.AccessTTL,
+		RefreshTTL: settings.RefreshTTL,
+	}
+	if settings.JWTSecret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return config, fmt.Errorf("生成 JWT 密钥失败: %v", err)
+		}
+		slog.Warn("未设置 JWT_SECRET，已生成随机密钥，重启后需要重新登录")
+		config.Secret = random
+	}
+	return config, nil
+}
+
+// signToken 为用户签发指定类型的令牌
+func signToken(config AuthConfig, userID uint, tokenType string, now time.Time) (string, error) {
+	ttl := config.AccessTTL
+	if tokenType == TokenTypeRefresh {
+		ttl = config.RefreshTTL
+	}
+
+	claims := TokenClaims{
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(config.Secret)
+	if err != nil {
+		return "", fmt.Errorf("签发令牌失败: %v", err)
+	}
+	return token, nil
+}
+
+// parseToken 校验令牌签名、有效期和类型，返回用户ID
+func parseToken(config AuthConfig, tokenString, tokenType string) (uint, error) {
+	var claims TokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return config.Secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return 0, ErrTokenExpired
+		}
+		return 0, ErrTokenInvalid
+	}
+	if claims.TokenType != tokenType {
+		return 0, ErrTokenInvalid
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+	if err != nil || userID == 0 {
+		return 0, ErrTokenInvalid
+	}
+	return uint(userID), nil
+}
+
+// AuthRequired 认证中间件
+// 从 Authorization: Bearer <token> 中解析访问令牌，加载当前用户并拒绝已禁用的用户
+func (s *Server) AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, tokenString, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(tokenString) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, Response{
+				Code:    401,
+				Message: "未登录",
+			})
+			return
+		}
+
+		userID, err := parseToken(s.auth, strings.TrimSpace(tokenString), TokenTypeAccess)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, Response{
+				Code:    401,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		// 每次请求都重新查询用户，禁用用户后已签发的令牌立即失效
+		user, err := loadActiveUser(c.Request.Context(), s.users, userID)
+		if err != nil {
+			status := authErrorStatus(err)
+			c.AbortWithStatusJSON(status, Response{
+				Code:    status,
+				Message: err.Error(),
+			})
+			return
+		}
+
+		c.Set(currentUserKey, user)
+		c.Next()
+	}
+}
+
+// currentUser 返回认证中间件保存的当前用户，未登录时返回 nil
+func currentUser(c *gin.Context) *User {
+	value, exists := c.Get(currentUserKey)
+	if !exists {
+		return nil
+	}
+	user, _ := value.(*User)
+	return user
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Register 用户注册
+// POST /auth/register
+func (s *Server) Register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := registerUser(c.Request.Context(), s.users, req)
+	if err != nil {
+		status := authErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "注册成功",
+		Data:    user,
+	})
+}
+
+// Login 用户登录，签发访问令牌和刷新令牌
+// POST /auth/login
+func (s *Server) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	tokens, err := loginUser(c.Request.Context(), s.users, s.auth, req)
+	if err != nil {
+		status := authErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "登录成功",
+		Data:    tokens,
+	})
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌
+// POST /auth/refresh
+func (s *Server) RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	tokens, err := refreshTokens(c.Request.Context(), s.users, s.auth, req.RefreshToken)
+	if err != nil {
+		status := authErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "刷新成功",
+		Data:    tokens,
+	})
+}
+
+// GetCurrentUser 查询当前登录用户
+// GET /auth/me
+func (s *Server) GetCurrentUser(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    currentUser(c),
+	})
+}
+
+// authErrorStatus 将认证业务错误映射为 HTTP 状态码
+func authErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUsernameTaken),
+		errors.Is(err, ErrPhoneTaken),
+		errors.Is(err, ErrEmailTaken),
+		errors.Is(err, ErrUserConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidCredentials),
+		errors.Is(err, ErrTokenInvalid),
+		errors.Is(err, ErrTokenExpired):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrUserBanned):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAuthConfigRandomSecret(t *testing.T) {
+	buf := captureDefaultLogger(t)
+
+	config, err := newAuthConfig(AuthSettings{AccessTTL: time.Minute, RefreshTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Secret) != 32 {
+		t.Errorf("随机密钥长度 = %d，期望 32", len(config.Secret))
+	}
+	lines := logLines(t, buf)
+	if len(lines) != 1 || lines[0]["level"] != "WARN" {
+		t.Errorf("未设置密钥时应输出一条警告日志: %v", lines)
+	}
+
+	// 设置了密钥时不输出警告
+	buf.Reset()
+	config, err = newAuthConfig(AuthSettings{JWTSecret: "configured-secret", AccessTTL: time.Minute, RefreshTTL: time.Hour})
+	if err != nil || string(config.Secret) != "configured-secret" {
+		t.Errorf("密钥 = %q, %v", config.Secret, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("设置了密钥时不应输出日志: %s", buf)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	ts := newTestServer(t)
+
+	req := RegisterRequest{
+		Username: "zhaoliu",
+		Password: "password123",
+		Phone:    "13800138009",
+		Email:    "ZhaoLiu@Example.com",
+	}
+	resp := ts.expect(http.MethodPost, "/auth/register", "", req, http.StatusCreated)
+	var user map[string]interface{}
+	resp.decode(t, &user)
+	if user["username"] != "zhaoliu" || user["role"] != RoleCustomer {
+		t.Errorf("注册的用户 = %v", user)
+	}
+	if user["nickname"] != "zhaoliu" {
+		t.Errorf("未填写昵称时应使用用户名，nickname = %v", user["nickname"])
+	}
+	if _, ok := user["password"]; ok {
+		t.Error("响应中不能包含密码")
+	}
+
+	// 新用户可以直接登录
+	ts.login("zhaoliu")
+
+	conflicts := []struct {
+		req     RegisterRequest
+		message string
+	}{
+		{RegisterRequest{Username: "zhaoliu", Password: "password123", Phone: "1", Email: "a@example.com"}, ErrUsernameTaken.Error()},
+		{RegisterRequest{Username: "other1", Password: "password123", Phone: "13800138009", Email: "b@example.com"}, ErrPhoneTaken.Error()},
+		{RegisterRequest{Username: "other2", Password: "password123", Phone: "2", Email: "zhaoliu@example.com"}, ErrEmailTaken.Error()},
+	}
+	for _, tc := range conflicts {
+		resp := ts.expect(http.MethodPost, "/auth/register", "", tc.req, http.StatusConflict)
+		if resp.Message != tc.message {
+			t.Errorf("message = %q，期望 %q", resp.Message, tc.message)
+		}
+	}
+
+	invalid := []RegisterRequest{
+		{Username: "ab", Password: "password123", Phone: "3", Email: "c@example.com"},
+		{Username: "shortpw", Password: "1234567", Phone: "3", Email: "c@example.com"},
+		{Username: "bademail", Password: "password123", Phone: "3", Email: "not-an-email"},
+		{Username: "nophone", Password: "password123", Email: "c@example.com"},
+	}
+	for _, req := range invalid {
+		ts.expect(http.MethodPost, "/auth/register", "", req, http.StatusBadRequest)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	ts := newTestServer(t)
+
+	// 用户名、手机号、邮箱都可以登录
+	for _, account := range []string{"zhangsan", "13800138001", "zhangsan@example.com"} {
+		resp := ts.expect(http.MethodPost, "/auth/login", "", LoginRequest{Account: account, Password: seedUserPassword}, http.StatusOK)
+		var tokens TokenResponse
+		resp.decode(t, &tokens)
+		if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.TokenType != "Bearer" {
+			t.Errorf("%s: 令牌 = %+v", account, tokens)
+		}
+		if tokens.ExpiresIn != 60 {
+			t.Errorf("%s: expires_in = %d，期望 60", account, tokens.ExpiresIn)
+		}
+		if tokens.User == nil || tokens.User.ID != fixtureZhangsanID {
+			t.Errorf("%s: user = %+v", account, tokens.User)
+		}
+	}
+
+	// 密码错误和用户不存在返回相同的错误，不暴露用户是否存在
+	for _, req := range []LoginRequest{
+		{Account: "zhangsan", Password: "wrong-password"},
+		{Account: "nobody", Password: seedUserPassword},
+	} {
+		resp := ts.expect(http.MethodPost, "/auth/login", "", req, http.StatusUnauthorized)
+		if resp.Message != ErrInvalidCredentials.Error() {
+			t.Errorf("message = %q", resp.Message)
+		}
+	}
+
+	ts.expect(http.MethodPost, "/auth/login", "", LoginRequest{Account: "zhangsan"}, http.StatusBadRequest)
+
+	// 禁用的用户不能登录，已签发的令牌也立即失效
+	token := ts.login("lisi")
+	ts.db.Model(&User{}).Where("id = ?", fixtureLisiID).Update("status", UserStatusBanned)
+	ts.expect(http.MethodPost, "/auth/login", "", LoginRequest{Account: "lisi", Password: seedUserPassword}, http.StatusForbidden)
+	ts.expect(http.MethodGet, "/auth/me", token, nil, http.StatusForbidden)
+}
+
+func TestRefreshToken(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodPost, "/auth/login", "", LoginRequest{Account: "wangwu", Password: seedUserPassword}, http.StatusOK)
+	var tokens TokenResponse
+	resp.decode(t, &tokens)
+
+	resp = ts.expect(http.MethodPost, "/auth/refresh", "", RefreshRequest{RefreshToken: tokens.RefreshToken}, http.StatusOK)
+	var refreshed TokenResponse
+	resp.decode(t, &refreshed)
+	if refreshed.AccessToken == "" || refreshed.User == nil || refreshed.User.ID != fixtureWangwuID {
+		t.Fatalf("刷新结果 = %+v", refreshed)
+	}
+	ts.expect(http.MethodGet, "/auth/me", refreshed.AccessToken, nil, http.StatusOK)
+
+	// 访问令牌不能用于刷新，刷新令牌也不能用于访问接口
+	ts.expect(http.MethodPost, "/auth/refresh", "", RefreshRequest{RefreshToken: tokens.AccessToken}, http.StatusUnauthorized)
+	ts.expect(http.MethodGet, "/auth/me", tokens.RefreshToken, nil, http.StatusUnauthorized)
+	ts.expect(http.MethodPost, "/auth/refresh", "", nil, http.StatusBadRequest)
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/auth/me", ts.login("operator"), nil, http.StatusOK)
+	var user User
+	resp.decode(t, &user)
+	if user.ID != fixtureOperatorID || user.Username != "operator" || user.Role != RoleOperator {
+		t.Errorf("当前用户 = %+v", user)
+	}
+}
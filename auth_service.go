@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 用户认证相关的业务错误
+var (
+	ErrUsernameTaken      = errors.New("用户名已被注册")
+	ErrPhoneTaken         = errors.New("手机号已被注册")
+	ErrEmailTaken         = errors.New("邮箱已被注册")
+	ErrUserConflict       = errors.New("用户名、手机号或邮箱已被注册")
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	ErrUserBanned         = errors.New("用户已被禁用")
+)
+
+// RegisterRequest 注册请求参数
+// 手机号和邮箱在表中是唯一索引，空字符串也会互相冲突，因此注册时要求必填
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,min=8,max=72"` // bcrypt 最多使用前 72 个字节
+	Phone    string `json:"phone" binding:"required,max=20"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Nickname string `json:"nickname" binding:"max=50"`
+}
+
+// LoginRequest 登录请求参数
+// account 可以是用户名、手机号或邮箱
+type LoginRequest struct {
+	Account  string `json:"account" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest 刷新令牌请求参数
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse 登录/刷新成功返回的令牌
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌有效期（秒）
+	User         *User  `json:"user"`
+}
+
+// dummyPasswordHash 账号不存在时也执行一次 bcrypt 比较，避免通过响应时间判断账号是否存在
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// hashPassword 使用 bcrypt 加密密码
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("密码加密失败: %v", err)
+	}
+	return string(hash), nil
+}
+
+// registerUser 注册用户，密码使用 bcrypt 加密后保存
+func registerUser(ctx context.Context, users UserRepository, req RegisterRequest) (*User, error) {
+	return createUser(ctx, users, req, RoleCustomer)
+}
+
+// createAdminUser 创建管理员，只能通过命令行调用，用于初始化第一个管理员账号
+func createAdminUser(ctx context.Context, users UserRepository, req RegisterRequest) (*User, error) {
+	return createUser(ctx, users, req, RoleAdmin)
+}
+
+// createUser 创建指定角色的用户，密码使用 bcrypt 加密后保存
+func createUser(ctx context.Context, users UserRepository, req RegisterRequest, role string) (*User, error) {
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := User{
+		Username: strings.TrimSpace(req.Username),
+		Phone:    strings.TrimSpace(req.Phone),
+		Email:    strings.ToLower(strings.TrimSpace(req.Email)),
+		Password: passwordHash,
+		Nickname: req.Nickname,
+		Status:   UserStatusNormal,
+		Role:     role,
+	}
+	if user.Nickname == "" {
+		user.Nickname = user.Username
+	}
+
+	if err := users.Create(ctx, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// loginUser 校验账号密码并签发令牌
+func loginUser(ctx context.Context, users UserRepository, config AuthConfig, req LoginRequest) (*TokenResponse, error) {
+	user, err := users.FindByAccount(ctx, strings.TrimSpace(req.Account))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Status == UserStatusBanned {
+		return nil, ErrUserBanned
+	}
+
+	return issueTokens(config, user)
+}
+
+// refreshTokens 使用刷新令牌换取新的访问令牌和刷新令牌
+func refreshTokens(ctx context.Context, users UserRepository, config AuthConfig, refreshToken string) (*TokenResponse, error) {
+	userID, err := parseToken(config, refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := loadActiveUser(ctx, users, userID)
+	if err != nil {
+		return nil, err
+	}
+	return issueTokens(config, user)
+}
+
+// loadActiveUser 查询用户并校验用户状态
+// 用户不存在（包括已删除）视为令牌无效
+func loadActiveUser(ctx context.Context, users UserRepository, userID uint) (*User, error) {
+	user, err := users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+	if user.Status == UserStatusBanned {
+		return nil, ErrUserBanned
+	}
+	return user, nil
+}
+
+// issueTokens 为用户签发访问令牌和刷新令牌
+func issueTokens(config AuthConfig, user *User) (*TokenResponse, error) {
+	now := time.Now()
+	accessToken, err := signToken(config, user.ID, TokenTypeAccess, now)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := signToken(config, user.ID, TokenTypeRefresh, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(config.AccessTTL / time.Second),
+		User:         user,
+	}, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCategories 查询分类树
+// GET /categories
+func (s *Server) GetCategories(c *gin.Context) {
+	tree, err := s.categories.Tree(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    tree,
+	})
+}
+
+// GetCategory 查询单个分类
+// GET /categories/:id
+func (s *Server) GetCategory(c *gin.Context) {
+	categoryIDStr := c.Param("id")
+	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的分类ID",
+		})
+		return
+	}
+
+	category, err := s.categories.FindByID(c.Request.Context(), uint(categoryID))
+	if err != nil {
+		status := categoryErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    category,
+	})
+}
+
+// GetCategoryProducts 查询分类下的商品（默认包含所有子孙分类的商品）
+// GET /categories/:id/products?include_descendants=false&page=1&page_size=20
+func (s *Server) GetCategoryProducts(c *gin.Context) {
+	categoryIDStr := c.Param("id")
+	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的分类ID",
+		})
+		return
+	}
+
+	includeDescendants := true
+	if value := c.Query("include_descendants"); value != "" {
+		includeDescendants, err = strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    400,
+				Message: "无效的 include_descendants 参数",
+			})
+			return
+		}
+	}
+
+	categoryIDs, err := s.categories.ResolveIDs(c.Request.Context(), uint(categoryID), includeDescendants)
+	if err != nil {
+		status := categoryErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	params, err := parseListParams(c, productListSpec)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	filter.CategoryIDs = categoryIDs
+	result, err := s.products.List(c.Request.Context(), filter, params)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    result,
+	})
+}
+
+// CreateCategory 创建分类
+// POST /categories
+func (s *Server) CreateCategory(c *gin.Context) {
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	category, err := s.categories.Create(c.Request.Context(), req)
+	if err != nil {
+		status := categoryErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "创建成功",
+		Data:    category,
+	})
+}
+
+// UpdateCategory 修改分类
+// PUT /categories/:id
+func (s *Server) UpdateCategory(c *gin.Context) {
+	categoryIDStr := c.Param("id")
+	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的分类ID",
+		})
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	category, err := s.categories.Update(c.Request.Context(), uint(categoryID), req)
+	if err != nil {
+		status := categoryErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    category,
+	})
+}
+
+// DeleteCategory 删除分类
+// DELETE /categories/:id
+func (s *Server) DeleteCategory(c *gin.Context) {
+	categoryIDStr := c.Param("id")
+	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的分类ID",
+		})
+		return
+	}
+
+	if err := s.categories.Delete(c.Request.Context(), uint(categoryID)); err != nil {
+		status := categoryErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "删除成功",
+	})
+}
+
+// categoryErrorStatus 将分类业务错误映射为 HTTP 状态码
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrCategoryParentInvalid),
+		errors.Is(err, ErrCategoryParentMissing):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrCategoryHasChildren),
+		errors.Is(err, ErrCategoryHasProducts):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetCategories(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/categories", "", nil, http.StatusOK)
+	var tree []Category
+	resp.decode(t, &tree)
+	if len(tree) != 2 || tree[0].ID != fixtureCategoryDigital || tree[1].ID != fixtureCategoryAccessory {
+		t.Fatalf("顶级分类 = %+v", tree)
+	}
+	if children := tree[0].Children; len(children) != 3 || children[0].ID != fixtureCategoryPhone || children[2].ID != fixtureCategoryLaptop {
+		t.Errorf("数码电子的子分类 = %+v", children)
+	}
+}
+
+func TestGetCategory(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, fmt.Sprintf("/categories/%d", fixtureCategoryPhone), "", nil, http.StatusOK)
+	var category Category
+	resp.decode(t, &category)
+	if category.Name != "手机" || category.ParentID == nil || *category.ParentID != fixtureCategoryDigital {
+		t.Errorf("分类 = %+v", category)
+	}
+}
+
+func TestGetCategoryProducts(t *testing.T) {
+	ts := newTestServer(t)
+
+	cases := []struct {
+		path string
+		ids  []uint
+	}{
+		// 默认包含子孙分类的商品
+		{fmt.Sprintf("/categories/%d/products?sort=price", fixtureCategoryDigital), []uint{fixtureAirPodsID, fixtureIPhoneID, fixtureMacBookID}},
+		{fmt.Sprintf("/categories/%d/products?include_descendants=false", fixtureCategoryDigital), []uint{}},
+		{fmt.Sprintf("/categories/%d/products", fixtureCategoryLaptop), []uint{fixtureMacBookID}},
+		{fmt.Sprintf("/categories/%d/products?max_price=5000", fixtureCategoryDigital), []uint{fixtureAirPodsID}},
+	}
+	for _, tc := range cases {
+		resp := ts.expect(http.MethodGet, tc.path, "", nil, http.StatusOK)
+		var page pageResult[Product]
+		resp.decode(t, &page)
+		if !sameIDs(productIDs(page.Items), tc.ids) {
+			t.Errorf("%s: 商品 = %v，期望 %v", tc.path, productIDs(page.Items), tc.ids)
+		}
+	}
+
+	ts.expect(http.MethodGet, "/categories/1/products?include_descendants=maybe", "", nil, http.StatusBadRequest)
+	ts.expect(http.MethodGet, "/categories/1/products?sort=name", "", nil, http.StatusBadRequest)
+}
+
+func TestCreateCategory(t *testing.T) {
+	ts := newTestServer(t)
+	operator := ts.login("operator")
+
+	parentID := uint(fixtureCategoryDigital)
+	resp := ts.expect(http.MethodPost, "/categories", operator, CategoryRequest{ParentID: &parentID, Name: "平板", Sort: 4}, http.StatusCreated)
+	var category Category
+	resp.decode(t, &category)
+	if category.ID == 0 || category.Name != "平板" || category.Status != CategoryStatusEnabled {
+		t.Errorf("新分类 = %+v", category)
+	}
+
+	missingID := uint(999)
+	resp = ts.expect(http.MethodPost, "/categories", operator, CategoryRequest{ParentID: &missingID, Name: "平板"}, http.StatusBadRequest)
+	if resp.Message != ErrCategoryParentMissing.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+	ts.expect(http.MethodPost, "/categories", operator, CategoryRequest{}, http.StatusBadRequest)
+	ts.expect(http.MethodPost, "/categories", ts.login("zhangsan"), CategoryRequest{Name: "平板"}, http.StatusForbidden)
+}
+
+func TestUpdateCategory(t *testing.T) {
+	ts := newTestServer(t)
+	operator := ts.login("operator")
+
+	// 把电脑移到配件下
+	parentID := uint(fixtureCategoryAccessory)
+	path := fmt.Sprintf("/categories/%d", fixtureCategoryLaptop)
+	resp := ts.expect(http.MethodPut, path, operator, CategoryRequest{ParentID: &parentID, Name: "笔记本电脑", Sort: 1}, http.StatusOK)
+	var category Category
+	resp.decode(t, &category)
+	if category.Name != "笔记本电脑" || category.ParentID == nil || *category.ParentID != fixtureCategoryAccessory {
+		t.Errorf("修改后的分类 = %+v", category)
+	}
+
+	// 上级分类不能是自身或子分类
+	childID := uint(fixtureCategoryPhone)
+	resp = ts.expect(http.MethodPut, "/categories/1", operator, CategoryRequest{ParentID: &childID, Name: "数码电子"}, http.StatusBadRequest)
+	if resp.Message != ErrCategoryParentInvalid.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+	selfID := uint(fixtureCategoryDigital)
+	ts.expect(http.MethodPut, "/categories/1", operator, CategoryRequest{ParentID: &selfID, Name: "数码电子"}, http.StatusBadRequest)
+	invalidStatus := int8(5)
+	ts.expect(http.MethodPut, "/categories/1", operator, CategoryRequest{Name: "数码电子", Status: &invalidStatus}, http.StatusBadRequest)
+}
+
+func TestDeleteCategory(t *testing.T) {
+	ts := newTestServer(t)
+	operator := ts.login("operator")
+
+	resp := ts.expect(http.MethodDelete, fmt.Sprintf("/categories/%d", fixtureCategoryDigital), operator, nil, http.StatusConflict)
+	if resp.Message != ErrCategoryHasChildren.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+	resp = ts.expect(http.MethodDelete, fmt.Sprintf("/categories/%d", fixtureCategoryLaptop), operator, nil, http.StatusConflict)
+	if resp.Message != ErrCategoryHasProducts.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+
+	// 商品移走后可以删除
+	ts.db.Model(&Product{}).Where("id = ?", fixtureMacBookID).Update("category_id", fixtureCategoryDigital)
+	ts.expect(http.MethodDelete, fmt.Sprintf("/categories/%d", fixtureCategoryLaptop), operator, nil, http.StatusOK)
+	ts.expect(http.MethodGet, fmt.Sprintf("/categories/%d", fixtureCategoryLaptop), "", nil, http.StatusNotFound)
+	ts.expect(http.MethodDelete, fmt.Sprintf("/categories/%d", fixtureCategoryAccessory), ts.login("lisi"), nil, http.StatusForbidden)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// 分类相关的业务错误
+var (
+	ErrCategoryNotFound      = errors.New("分类不存在")
+	ErrCategoryParentInvalid = errors.New("上级分类不能是自身或其子分类")
+	ErrCategoryParentMissing = errors.New("上级分类不存在")
+	ErrCategoryHasChildren   = errors.New("分类下还有子分类，不能删除")
+	ErrCategoryHasProducts   = errors.New("分类下还有商品，不能删除")
+)
+
+// CategoryRequest 创建/修改分类请求参数
+type CategoryRequest struct {
+	ParentID *uint  `json:"parent_id"`
+	Name     string `json:"name" binding:"required,max=50"`
+	Sort     int    `json:"sort"`
+	Status   *int8  `json:"status" binding:"omitempty,oneof=0 1"`
+}
+
+// queryCategories 查询所有分类，按 sort、id 排序
+func queryCategories(db *gorm.DB) ([]Category, error) {
+	var categories []Category
+	if err := db.Order("sort ASC, id ASC").Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("查询分类失败: %v", err)
+	}
+	return categories, nil
+}
+
+// buildCategoryTree 将扁平的分类列表组装成树
+// 上级分类不在列表中的分类（例如上级已被删除）作为顶级分类返回，避免数据丢失
+func buildCategoryTree(categories []Category) []Category {
+	exists := make(map[uint]bool, len(categories))
+	childrenOf := make(map[uint][]Category)
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+
+	var roots []Category
+	for _, category := range categories {
+		if category.ParentID != nil && exists[*category.ParentID] {
+			childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].Sort != nodes[j].Sort {
+				return nodes[i].Sort < nodes[j].Sort
+			}
+			return nodes[i].ID < nodes[j].ID
+		})
+		for i := range nodes {
+			nodes[i].Children = attach(childrenOf[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots)
+}
+
+// categoryDescendantIDs 返回指定分类及其所有子孙分类的 ID
+func categoryDescendantIDs(categories []Category, rootID uint) []uint {
+	childrenOf := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			childrenOf[*category.ParentID] = append(childrenOf[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	visited := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range childrenOf[ids[i]] {
+			if !visited[childID] {
+				visited[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids
+}
+
+// queryCategoryTree 查询分类树
+func queryCategoryTree(db *gorm.DB) ([]Category, error) {
+	categories, err := queryCategories(db)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// resolveCategoryIDs 返回查询分类商品时需要匹配的分类 ID
+// includeDescendants 为 true 时包含所有子孙分类
+func resolveCategoryIDs(db *gorm.DB, categoryID uint, includeDescendants bool) ([]uint, error) {
+	categories, err := queryCategories(db)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, category := range categories {
+		if category.ID == categoryID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrCategoryNotFound
+	}
+
+	if !includeDescendants {
+		return []uint{categoryID}, nil
+	}
+	return categoryDescendantIDs(categories, categoryID), nil
+}
+
+// validateCategoryParent 校验上级分类存在，且不是分类自身或其子孙分类
+// categoryID 为 0 表示新建分类
+func validateCategoryParent(tx *gorm.DB, categoryID uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+
+	categories, err := queryCategories(tx)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, category := range categories {
+		if category.ID == *parentID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrCategoryParentMissing
+	}
+
+	if categoryID != 0 {
+		for _, id := range categoryDescendantIDs(categories, categoryID) {
+			if id == *parentID {
+				return ErrCategoryParentInvalid
+			}
+		}
+	}
+	return nil
+}
+
+// createCategory 创建分类
+func createCategory(db *gorm.DB, req CategoryRequest) (*Category, error) {
+	category := Category{
+		ParentID: req.ParentID,
+		Name:     req.Name,
+		Sort:     req.Sort,
+		Status:   CategoryStatusEnabled,
+	}
+	if req.Status != nil {
+		category.Status = *req.Status
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := validateCategoryParent(tx, 0, req.ParentID); err != nil {
+			return err
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return fmt.Errorf("创建分类失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// updateCategory 修改分类
+func updateCategory(db *gorm.DB, categoryID uint, req CategoryRequest) (*Category, error) {
+	var category Category
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&category, categoryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCategoryNotFound
+			}
+			return fmt.Errorf("查询分类失败: %v", err)
+		}
+		if err := validateCategoryParent(tx, category.ID, req.ParentID); err != nil {
+			return err
+		}
+
+		category.ParentID = req.ParentID
+		category.Name = req.Name
+		category.Sort = req.Sort
+		if req.Status != nil {
+			category.Status = *req.Status
+		}
+		// 使用 Select 指定字段，保证 parent_id 可以被更新为 NULL、status 可以被更新为 0
+		if err := tx.Model(&category).
+			Select("parent_id", "name", "sort", "status").
+			Updates(&category).Error; err != nil {
+			return fmt.Errorf("修改分类失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// deleteCategory 删除分类
+// 分类下还有子分类或商品时拒绝删除
+func deleteCategory(db *gorm.DB, categoryID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var category Category
+		if err := tx.First(&category, categoryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCategoryNotFound
+			}
+			return fmt.Errorf("查询分类失败: %v", err)
+		}
+
+		var childCount int64
+		if err := tx.Model(&Category{}).Where("parent_id = ?", category.ID).Count(&childCount).Error; err != nil {
+			return fmt.Errorf("查询子分类失败: %v", err)
+		}
+		if childCount > 0 {
+			return ErrCategoryHasChildren
+		}
+
+		var productCount int64
+		if err := tx.Model(&Product{}).Where("category_id = ?", category.ID).Count(&productCount).Error; err != nil {
+			return fmt.Errorf("查询分类商品失败: %v", err)
+		}
+		if productCount > 0 {
+			return ErrCategoryHasProducts
+		}
+
+		if err := tx.Delete(&category).Error; err != nil {
+			return fmt.Errorf("删除分类失败: %v", err)
+		}
+		return nil
+	})
+}
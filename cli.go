@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// 命令行退出码，供部署脚本判断执行结果
+const (
+	exitOK       = 0 // 执行成功
+	exitFailure  = 1 // 执行失败（连接数据库失败、迁移失败等）
+	exitUsage    = 2 // 命令或参数错误
+	exitNotReady = 3 // db check: 数据库可以连接，但有未执行或被修改的迁移
+)
+
+const cliUsage = `用法: DataBaseDesign [-config 配置文件] <命令> [参数]
+
+命令:
+  serve [-migrate=true]          启动 HTTP 服务（默认命令），-migrate=false 时启动前不执行迁移
+  migrate up [N]                 执行未执行的迁移（可指定步数）
+  migrate down [N]               回滚最近 N 个迁移（默认 1）
+  migrate status                 查看迁移状态
+  seed [-users N] [-reset] ...   生成测试数据，-h 查看全部参数
+  db check                       检查数据库连接和迁移状态
+  user create-admin -username 用户名 -phone 手机号 -email 邮箱 [-nickname 昵称]
+                                 创建管理员，密码从 ADMIN_PASSWORD 环境变量或标准输入读取
+  help                           显示本帮助
+
+配置从 -config 指定的文件（默认为 CONFIG_FILE 环境变量或当前目录下的 config.yaml）读取，
+环境变量（DB_DRIVER、DB_PASSWORD、PORT、GIN_MODE 等）优先于配置文件，见 config.example.yaml
+
+退出码: 0 成功，1 执行失败（包括配置错误），2 命令或参数错误，3 数据库迁移不是最新（db check）
+`
+
+// cliCommands 子命令，配置加载并校验通过后执行
+var cliCommands = map[string]func(config *Config, args []string) int{
+	"serve": runServeCommand,
+	"migrate": func(config *Config, args []string) int {
+		return withDB(config, func(db *gorm.DB) int { return runMigrateCommand(db, args) })
+	},
+	"seed": func(config *Config, args []string) int {
+		return withDB(config, func(db *gorm.DB) int { return runSeedCommand(db, args) })
+	},
+	"db":   runDBCommand,
+	"user": runUserCommand,
+}
+
+// runCLI 解析全局参数和子命令并执行，返回进程退出码
+func runCLI(args []string) int {
+	flags := flag.NewFlagSet("DataBaseDesign", flag.ContinueOnError)
+	configPath := flags.String("config", "", "配置文件路径")
+	flags.Usage = func() { fmt.Print(cliUsage) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	args = flags.Args()
+
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "help" {
+		fmt.Print(cliUsage)
+		return exitOK
+	}
+	run, ok := cliCommands[command]
+	if !ok {
+		fmt.Printf("未知的命令: %s\n\n%s", command, cliUsage)
+		return exitUsage
+	}
+
+	// 配置有误时列出所有问题后退出，不连接数据库
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return exitFailure
+	}
+	slog.SetDefault(newLogger(config.Log, os.Stderr))
+	return run(config, args)
+}
+
+// openDB 按配置连接数据库，SQL 日志输出到结构化日志
+func openDB(config *Config) (*gorm.DB, error) {
+	db, err := connectDB(config.DB)
+	if err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %v", err)
+	}
+	db.Logger = newGormLogger(slog.Default(), config.Log)
+	fmt.Println("✓ 数据库连接成功！")
+	return db, nil
+}
+
+// withDB 连接数据库后执行命令，命令结束后关闭连接
+func withDB(config *Config, run func(db *gorm.DB) int) int {
+	db, err := openDB(config)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return exitFailure
+	}
+	defer closeDB(db)
+	return run(db)
+}
+
+// closeDB 关闭数据库连接
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// runDBCommand 执行 db 命令
+// 用法: db check
+func runDBCommand(config *Config, args []string) int {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Println("用法: db check")
+		return exitUsage
+	}
+	return withDB(config, checkDatabase)
+}
+
+// checkDatabase 检查迁移状态，所有迁移都已执行且没有被修改时返回 exitOK
+// 数据库连接和 Ping 已经在 openDB 中完成
+func checkDatabase(db *gorm.DB) int {
+	statuses, err := migrationStatus(db)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return exitFailure
+	}
+
+	for _, status := range statuses {
+		if status.State != MigrationStateApplied {
+			fmt.Printf("  %04d_%s: %s\n", status.Version, status.Name, status.State)
+		}
+	}
+	summary := summarizeMigrations(statuses)
+	if summary.UpToDate() {
+		fmt.Printf("✓ 数据库迁移是最新版本（共 %d 个）\n", summary.Total)
+		return exitOK
+	}
+	fmt.Printf("✗ 数据库迁移不是最新版本: %d 个已执行，%d 个未执行，%d 个被修改，%d 个文件缺失\n",
+		summary.Applied, summary.Pending, summary.Modified, summary.Missing)
+	return exitNotReady
+}
+
+// runUserCommand 执行 user 命令
+// 用法: user create-admin -username 用户名 -phone 手机号 -email 邮箱 [-nickname 昵称]
+func runUserCommand(config *Config, args []string) int {
+	if len(args) == 0 || args[0] != "create-admin" {
+		fmt.Println("用法: user create-admin -username 用户名 -phone 手机号 -email 邮箱 [-nickname 昵称]")
+		return exitUsage
+	}
+
+	var req RegisterRequest
+	flags := flag.NewFlagSet("user create-admin", flag.ContinueOnError)
+	flags.StringVar(&req.Username, "username", "", "用户名")
+	flags.StringVar(&req.Phone, "phone", "", "手机号")
+	flags.StringVar(&req.Email, "email", "", "邮箱")
+	flags.StringVar(&req.Nickname, "nickname", "", "昵称，默认与用户名相同")
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+
+	// 密码不通过命令行参数传入，避免出现在进程列表和 shell 历史中
+	password, err := readAdminPassword(os.Stdin)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return exitUsage
+	}
+	req.Password = password
+
+	// 与注册接口使用相同的参数校验规则
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		fmt.Printf("✗ 参数错误: %v\n", err)
+		return exitUsage
+	}
+
+	return withDB(config, func(db *gorm.DB) int {
+		user, err := createAdminUser(context.Background(), NewGormRepositories(db).Users, req)
+		if err != nil {
+			fmt.Printf("✗ 创建管理员失败: %v\n", err)
+			if errors.Is(err, ErrUsernameTaken) || errors.Is(err, ErrPhoneTaken) ||
+				errors.Is(err, ErrEmailTaken) || errors.Is(err, ErrUserConflict) {
+				return exitUsage
+			}
+			return exitFailure
+		}
+		fmt.Printf("✓ 已创建管理员 %s (ID: %d)\n", user.Username, user.ID)
+		return exitOK
+	})
+}
+
+// readAdminPassword 读取管理员密码：优先使用 ADMIN_PASSWORD 环境变量，否则从标准输入读取一行
+func readAdminPassword(stdin io.Reader) (string, error) {
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+
+	fmt.Print("请输入管理员密码: ")
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("读取密码失败: 请设置 ADMIN_PASSWORD 环境变量或通过标准输入传入密码")
+	}
+	fmt.Println()
+	return strings.TrimRight(line, "\r\n"), nil
+}
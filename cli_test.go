@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTestDatabase 生成配置文件，让命令行使用临时目录中的 SQLite 数据库文件
+func useTestDatabase(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cli.db")
+	configFile := filepath.Join(dir, "config.yaml")
+	content := "database:\n  driver: sqlite\n  name: " + path + "\n"
+	if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", configFile)
+	return path
+}
+
+func TestCLIUsageErrors(t *testing.T) {
+	useTestDatabase(t)
+
+	cases := [][]string{
+		{"bogus"},
+		{"db"},
+		{"db", "repair"},
+		{"user"},
+		{"user", "delete"},
+		{"migrate"},
+		{"migrate", "sideways"},
+		{"migrate", "up", "0"},
+		{"seed", "-users", "-1"},
+		{"serve", "-port", "80"},
+	}
+	for _, args := range cases {
+		if code := runCLI(args); code != exitUsage {
+			t.Errorf("%v: 退出码 = %d，期望 %d", args, code, exitUsage)
+		}
+	}
+	for _, args := range [][]string{{"help"}, {"-h"}} {
+		if code := runCLI(args); code != exitOK {
+			t.Errorf("%v: 退出码 = %d", args, code)
+		}
+	}
+}
+
+func TestCLIDatabaseCommands(t *testing.T) {
+	useTestDatabase(t)
+
+	steps := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"db", "check"}, exitNotReady},
+		{[]string{"migrate", "up"}, exitOK},
+		{[]string{"db", "check"}, exitOK},
+		{[]string{"migrate", "status"}, exitOK},
+		{[]string{"seed", "-users", "3", "-products", "5", "-orders", "10"}, exitOK},
+		// 已有数据时跳过，不算失败
+		{[]string{"seed"}, exitOK},
+		{[]string{"seed", "-reset", "-users", "2"}, exitOK},
+		{[]string{"migrate", "down", "1"}, exitOK},
+		{[]string{"db", "check"}, exitNotReady},
+	}
+	for _, step := range steps {
+		if code := runCLI(step.args); code != step.code {
+			t.Fatalf("%v: 退出码 = %d，期望 %d", step.args, code, step.code)
+		}
+	}
+
+	// 环境变量覆盖配置文件，配置有误时不执行命令
+	t.Setenv("DB_DRIVER", "oracle")
+	if code := runCLI([]string{"db", "check"}); code != exitFailure {
+		t.Errorf("不支持的驱动: 退出码 = %d，期望 %d", code, exitFailure)
+	}
+	if code := runCLI([]string{"-config", "missing.yaml", "db", "check"}); code != exitFailure {
+		t.Errorf("配置文件不存在: 退出码 = %d，期望 %d", code, exitFailure)
+	}
+}
+
+func TestCLICreateAdmin(t *testing.T) {
+	useTestDatabase(t)
+	if code := runCLI([]string{"migrate", "up"}); code != exitOK {
+		t.Fatalf("migrate up: 退出码 = %d", code)
+	}
+
+	t.Setenv("ADMIN_PASSWORD", "admin-password")
+	args := []string{"user", "create-admin", "-username", "root", "-phone", "13900000000", "-email", "Root@Example.com"}
+	if code := runCLI(args); code != exitOK {
+		t.Fatalf("创建管理员: 退出码 = %d", code)
+	}
+
+	config, err := loadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := openDB(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB(db)
+	tokens, err := loginUser(context.Background(), NewGormRepositories(db).Users, AuthConfig{Secret: []byte("cli-test-secret")},
+		LoginRequest{Account: "root@example.com", Password: "admin-password"})
+	if err != nil {
+		t.Fatalf("管理员登录失败: %v", err)
+	}
+	if tokens.User.Role != RoleAdmin || tokens.User.Nickname != "root" {
+		t.Errorf("管理员 = %+v", tokens.User)
+	}
+
+	// 用户名重复、参数不合法
+	if code := runCLI(args); code != exitUsage {
+		t.Errorf("重复创建: 退出码 = %d，期望 %d", code, exitUsage)
+	}
+	if code := runCLI([]string{"user", "create-admin", "-username", "ab", "-phone", "1", "-email", "a@example.com"}); code != exitUsage {
+		t.Errorf("用户名过短: 退出码 = %d，期望 %d", code, exitUsage)
+	}
+	t.Setenv("ADMIN_PASSWORD", "short")
+	if code := runCLI([]string{"user", "create-admin", "-username", "other", "-phone", "2", "-email", "b@example.com"}); code != exitUsage {
+		t.Errorf("密码过短: 退出码 = %d，期望 %d", code, exitUsage)
+	}
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-yaml"
+)
+
+// defaultConfigFile 未指定配置文件时读取的默认文件，不存在时只使用默认值和环境变量
+const defaultConfigFile = "config.yaml"
+
+// 日志级别
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// Config 应用配置
+// 优先级：环境变量 > 配置文件 > 默认值，配置文件格式见 config.example.yaml
+type Config struct {
+	Server  ServerConfig  `yaml:"server"`
+	DB      DBConfig      `yaml:"database"`
+	Auth    AuthSettings  `yaml:"auth"`
+	Orders  OrderConfig   `yaml:"orders"`
+	Log     LogConfig     `yaml:"log"`
+	Tracing TracingConfig `yaml:"tracing"`
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Port        string   `yaml:"port"`         // 监听端口，默认 8080
+	Mode        string   `yaml:"mode"`         // gin 运行模式: debug（默认）、release、test
+	CORSOrigins []string `yaml:"cors_origins"` // 允许跨域访问的来源，"*" 表示允许所有来源
+	AutoMigrate bool     `yaml:"auto_migrate"` // 使用 AutoMigrate 代替版本化迁移，仅限开发环境
+
+	// 超时配置，0 表示不限制
+	ReadTimeout     time.Duration `yaml:"read_timeout"`     // 读取请求（含请求体）的超时时间，默认 15s
+	WriteTimeout    time.Duration `yaml:"write_timeout"`    // 写入响应的超时时间，默认 30s
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`     // keep-alive 连接的最大空闲时间，默认 60s
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // 收到退出信号后等待处理中请求完成的最长时间，默认 30s
+}
+
+// AuthSettings 认证配置，启动时转换为 AuthConfig
+type AuthSettings struct {
+	JWTSecret  string        `yaml:"jwt_secret"`  // 为空时生成随机密钥（release 模式下必须设置）
+	AccessTTL  time.Duration `yaml:"access_ttl"`  // 访问令牌有效期，默认 15m
+	RefreshTTL time.Duration `yaml:"refresh_ttl"` // 刷新令牌有效期，默认 168h
+}
+
+// OrderConfig 订单配置
+type OrderConfig struct {
+	PendingTTL     time.Duration `yaml:"pending_ttl"`     // 待支付订单的有效期，超时自动取消并释放预占的库存，默认 30m，0 表示不自动取消
+	ExpiryInterval time.Duration `yaml:"expiry_interval"` // 检查超时订单的间隔，默认 1m
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level              string        `yaml:"level"`                // debug、info（默认）、warn、error
+	Format             string        `yaml:"format"`               // text（默认）或 json
+	SQL                bool          `yaml:"sql"`                  // 记录每条 SQL，默认关闭，仅建议在开发环境开启
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"` // 超过该时间的 SQL 记录为慢查询，默认 200ms，0 表示不记录
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`     // none（默认）、stdout、otlp
+	Endpoint    string  `yaml:"endpoint"`     // OTLP/HTTP 地址，例如 http://localhost:4318，exporter 为 otlp 时必须设置
+	ServiceName string  `yaml:"service_name"` // 上报的服务名，默认 DataBaseDesign
+	SampleRatio float64 `yaml:"sample_ratio"` // 采样比例 0~1，默认 1（全部采样）；上游已采样的请求始终采样
+}
+
+// ConfigError 配置校验错误，列出所有无效或缺失的配置项
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "配置错误:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// defaultConfig 返回默认配置
+// 数据库的端口、用户、库名默认值随驱动变化，在 applyDriverDefaults 中填充；数据库密码没有默认值
+func defaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			Mode:            gin.DebugMode,
+			CORSOrigins:     []string{"*"},
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		DB: DBConfig{
+			Driver:       DriverMySQL,
+			Host:         "127.0.0.1",
+			MaxOpenConns: 100,
+			MaxIdleConns: 10,
+		},
+		Auth: AuthSettings{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 7 * 24 * time.Hour,
+		},
+		Orders: OrderConfig{
+			PendingTTL:     30 * time.Minute,
+			ExpiryInterval: time.Minute,
+		},
+		Log: LogConfig{
+			Level:              LogLevelInfo,
+			Format:             LogFormatText,
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			ServiceName: serviceName,
+			SampleRatio: 1,
+		},
+	}
+}
+
+// loadConfig 加载配置：默认值 -> 配置文件 -> 环境变量，然后校验
+// path 为空时读取 CONFIG_FILE 环境变量指定的文件，都没有指定时读取当前目录下的 config.yaml（不存在则跳过）
+func loadConfig(path string) (*Config, error) {
+	config := defaultConfig()
+
+	explicit := true
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		path, explicit = defaultConfigFile, false
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.UnmarshalWithOptions(data, &config, yaml.Strict()); err != nil {
+			// 错误默认带有出错位置附近的源文件片段，可能包含数据库密码等敏感配置，只保留行列号和错误原因
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %s", path, yaml.FormatError(err, false, false))
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+		// 没有配置文件，只使用默认值和环境变量
+	default:
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	var problems []string
+	for _, binding := range config.envBindings() {
+		value := os.Getenv(binding.key)
+		if value == "" {
+			continue
+		}
+		if err := binding.set(value); err != nil {
+			problems = append(problems, fmt.Sprintf("环境变量 %s: %v", binding.key, err))
+		}
+	}
+
+	config.applyDriverDefaults()
+	problems = append(problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	return &config, nil
+}
+
+// envBinding 环境变量与配置项的对应关系
+type envBinding struct {
+	key string
+	set func(value string) error
+}
+
+// envBindings 返回可以通过环境变量覆盖的配置项
+func (c *Config) envBindings() []envBinding {
+	return []envBinding{
+		{"PORT", stringVar(&c.Server.Port)},
+		{"GIN_MODE", stringVar(&c.Server.Mode)},
+		{"CORS_ORIGINS", listVar(&c.Server.CORSOrigins)},
+		{"DB_AUTO_MIGRATE", boolVar(&c.Server.AutoMigrate)},
+		{"HTTP_READ_TIMEOUT", durationVar(&c.Server.ReadTimeout)},
+		{"HTTP_WRITE_TIMEOUT", durationVar(&c.Server.WriteTimeout)},
+		{"HTTP_IDLE_TIMEOUT", durationVar(&c.Server.IdleTimeout)},
+		{"SHUTDOWN_TIMEOUT", durationVar(&c.Server.ShutdownTimeout)},
+
+		{"DB_DRIVER", stringVar(&c.DB.Driver)},
+		{"DB_HOST", stringVar(&c.DB.Host)},
+		{"DB_PORT", stringVar(&c.DB.Port)},
+		{"DB_USER", stringVar(&c.DB.User)},
+		{"DB_PASSWORD", stringVar(&c.DB.Password)},
+		{"DB_NAME", stringVar(&c.DB.Database)},
+		{"DB_SSLMODE", stringVar(&c.DB.SSLMode)},
+		{"DB_SSLROOTCERT", stringVar(&c.DB.SSLRootCert)},
+		{"DB_MAX_OPEN_CONNS", intVar(&c.DB.MaxOpenConns)},
+		{"DB_MAX_IDLE_CONNS", intVar(&c.DB.MaxIdleConns)},
+		{"DB_CONN_MAX_LIFETIME", durationVar(&c.DB.ConnMaxLifetime)},
+		{"DB_CONN_MAX_IDLE_TIME", durationVar(&c.DB.ConnMaxIdleTime)},
+
+		{"JWT_SECRET", stringVar(&c.Auth.JWTSecret)},
+		{"JWT_ACCESS_TTL", durationVar(&c.Auth.AccessTTL)},
+		{"JWT_REFRESH_TTL", durationVar(&c.Auth.RefreshTTL)},
+
+		{"ORDER_PENDING_TTL", durationVar(&c.Orders.PendingTTL)},
+		{"ORDER_EXPIRY_INTERVAL", durationVar(&c.Orders.ExpiryInterval)},
+
+		{"LOG_LEVEL", stringVar(&c.Log.Level)},
+		{"LOG_FORMAT", stringVar(&c.Log.Format)},
+		{"LOG_SQL", boolVar(&c.Log.SQL)},
+		{"LOG_SLOW_QUERY_THRESHOLD", durationVar(&c.Log.SlowQueryThreshold)},
+
+		// 与 OpenTelemetry SDK 的标准环境变量同名
+		{"OTEL_TRACES_EXPORTER", stringVar(&c.Tracing.Exporter)},
+		{"OTEL_EXPORTER_OTLP_ENDPOINT", stringVar(&c.Tracing.Endpoint)},
+		{"OTEL_SERVICE_NAME", stringVar(&c.Tracing.ServiceName)},
+		{"OTEL_TRACES_SAMPLER_ARG", floatVar(&c.Tracing.SampleRatio)},
+	}
+}
+
+func stringVar(p *string) func(string) error {
+	return func(value string) error {
+		*p = value
+		return nil
+	}
+}
+
+func intVar(p *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q 不是整数", value)
+		}
+		*p = n
+		return nil
+	}
+}
+
+func boolVar(p *bool) func(string) error {
+	return func(value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q 不是 true 或 false", value)
+		}
+		*p = b
+		return nil
+	}
+}
+
+func floatVar(p *float64) func(string) error {
+	return func(value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q 不是数字", value)
+		}
+		*p = f
+		return nil
+	}
+}
+
+func durationVar(p *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q 不是有效的时长（例如 30s、15m、1h）", value)
+		}
+		*p = d
+		return nil
+	}
+}
+
+// listVar 解析逗号分隔的列表
+func listVar(p *[]string) func(string) error {
+	return func(value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*p = items
+		return nil
+	}
+}
+
+// applyDriverDefaults 填充随数据库驱动变化的默认值
+func (c *Config) applyDriverDefaults() {
+	db := &c.DB
+	switch db.Driver {
+	case DriverMySQL:
+		setDefault(&db.Port, "3306")
+		setDefault(&db.User, "root")
+		setDefault(&db.Database, "table_design")
+	case DriverPostgres:
+		setDefault(&db.Port, "5432")
+		setDefault(&db.User, "postgres")
+		setDefault(&db.Database, "table_design")
+		setDefault(&db.SSLMode, "disable")
+	case DriverSQLite:
+		setDefault(&db.Database, "table_design.db")
+	}
+}
+
+func setDefault(p *string, value string) {
+	if *p == "" {
+		*p = value
+	}
+}
+
+// validate 校验配置，返回所有问题；错误信息中不包含密码等敏感配置的值
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// 服务
+	if !validPort(c.Server.Port) {
+		add("server.port（PORT）必须是 1-65535 之间的端口号，当前为 %q", c.Server.Port)
+	}
+	switch c.Server.Mode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		add("server.mode（GIN_MODE）必须是 debug、release 或 test，当前为 %q", c.Server.Mode)
+	}
+	if c.Server.AutoMigrate && c.Server.Mode == gin.ReleaseMode {
+		add("server.auto_migrate（DB_AUTO_MIGRATE）不能在 release 模式下开启，请使用 migrate 命令")
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		add("server.cors_origins（CORS_ORIGINS）不能为空，允许所有来源请设置为 \"*\"")
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin != "*" && !validOrigin(origin) {
+			add("server.cors_origins（CORS_ORIGINS）中的 %q 不是有效的来源，格式为 https://example.com[:port]", origin)
+		}
+	}
+	if c.Server.ReadTimeout < 0 {
+		add("server.read_timeout（HTTP_READ_TIMEOUT）不能小于 0")
+	}
+	if c.Server.WriteTimeout < 0 {
+		add("server.write_timeout（HTTP_WRITE_TIMEOUT）不能小于 0")
+	}
+	if c.Server.IdleTimeout < 0 {
+		add("server.idle_timeout（HTTP_IDLE_TIMEOUT）不能小于 0")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout（SHUTDOWN_TIMEOUT）必须大于 0")
+	}
+
+	// 数据库
+	db := c.DB
+	switch db.Driver {
+	case DriverMySQL, DriverPostgres:
+		if db.Host == "" {
+			add("database.host（DB_HOST）未设置")
+		}
+		if !validPort(db.Port) {
+			add("database.port（DB_PORT）必须是 1-65535 之间的端口号，当前为 %q", db.Port)
+		}
+		if db.Password == "" {
+			add("database.password（DB_PASSWORD）未设置")
+		}
+	case DriverSQLite:
+	default:
+		add("database.driver（DB_DRIVER）必须是 %s、%s 或 %s，当前为 %q", DriverMySQL, DriverPostgres, DriverSQLite, db.Driver)
+	}
+	if db.Driver == DriverPostgres {
+		if !contains(postgresSSLModes, db.SSLMode) {
+			add("database.sslmode（DB_SSLMODE）必须是 %s 之一，当前为 %q", strings.Join(postgresSSLModes, "、"), db.SSLMode)
+		} else if (db.SSLMode == "verify-ca" || db.SSLMode == "verify-full") && db.SSLRootCert == "" {
+			add("database.sslrootcert（DB_SSLROOTCERT）未设置，sslmode=%s 时必须指定 CA 证书路径", db.SSLMode)
+		}
+	}
+	if db.MaxOpenConns < 0 {
+		add("database.max_open_conns（DB_MAX_OPEN_CONNS）不能小于 0")
+	}
+	if db.MaxIdleConns < 0 {
+		add("database.max_idle_conns（DB_MAX_IDLE_CONNS）不能小于 0")
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		add("database.max_idle_conns（DB_MAX_IDLE_CONNS）不能大于 max_open_conns（%d）", db.MaxOpenConns)
+	}
+	if db.ConnMaxLifetime < 0 {
+		add("database.conn_max_lifetime（DB_CONN_MAX_LIFETIME）不能小于 0")
+	}
+	if db.ConnMaxIdleTime < 0 {
+		add("database.conn_max_idle_time（DB_CONN_MAX_IDLE_TIME）不能小于 0")
+	}
+
+	// 认证
+	if c.Auth.JWTSecret == "" && c.Server.Mode == gin.ReleaseMode {
+		add("auth.jwt_secret（JWT_SECRET）未设置，release 模式下必须设置")
+	}
+	if c.Auth.JWTSecret != "" && len(c.Auth.JWTSecret) < 32 {
+		add("auth.jwt_secret（JWT_SECRET）长度不能少于 32 个字符")
+	}
+	if c.Auth.AccessTTL <= 0 {
+		add("auth.access_ttl（JWT_ACCESS_TTL）必须大于 0")
+	}
+	if c.Auth.RefreshTTL <= c.Auth.AccessTTL {
+		add("auth.refresh_ttl（JWT_REFRESH_TTL）必须大于 access_ttl")
+	}
+
+	// 订单
+	if c.Orders.PendingTTL < 0 {
+		add("orders.pending_ttl（ORDER_PENDING_TTL）不能小于 0")
+	}
+	if c.Orders.PendingTTL > 0 && c.Orders.ExpiryInterval <= 0 {
+		add("orders.expiry_interval（ORDER_EXPIRY_INTERVAL）必须大于 0")
+	}
+
+	// 日志
+	switch c.Log.Level {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		add("log.level（LOG_LEVEL）必须是 debug、info、warn 或 error，当前为 %q", c.Log.Level)
+	}
+	if c.Log.Format != LogFormatText && c.Log.Format != LogFormatJSON {
+		add("log.format（LOG_FORMAT）必须是 text 或 json，当前为 %q", c.Log.Format)
+	}
+	if c.Log.SlowQueryThreshold < 0 {
+		add("log.slow_query_threshold（LOG_SLOW_QUERY_THRESHOLD）不能小于 0")
+	}
+
+	// 链路追踪
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("tracing.endpoint（OTEL_EXPORTER_OTLP_ENDPOINT）必须是 http:// 或 https:// 开头的地址，当前为 %q", c.Tracing.Endpoint)
+		}
+	default:
+		add("tracing.exporter（OTEL_TRACES_EXPORTER）必须是 none、stdout 或 otlp，当前为 %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.ServiceName == "" {
+		add("tracing.service_name（OTEL_SERVICE_NAME）不能为空")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio（OTEL_TRACES_SAMPLER_ARG）必须在 0 到 1 之间，当前为 %g", c.Tracing.SampleRatio)
+	}
+	return problems
+}
+
+// validPort 是否为有效的端口号
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+// validOrigin 是否为有效的跨域来源，只包含协议、主机和端口
+func validOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.User == nil
+}
+
+func contains(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useConfigEnv 在空的临时目录中运行，并清空所有配置相关的环境变量
+func useConfigEnv(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("CONFIG_FILE", "")
+	var config Config
+	for _, binding := range config.envBindings() {
+		t.Setenv(binding.key, "")
+	}
+}
+
+// writeConfigFile 在当前目录写入配置文件
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// configProblems 加载配置，期望返回 ConfigError
+func configProblems(t *testing.T, path string) []string {
+	t.Helper()
+	_, err := loadConfig(path)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("期望配置错误，得到 %v", err)
+	}
+	return configErr.Problems
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	useConfigEnv(t)
+	t.Setenv("DB_PASSWORD", "secret")
+
+	config, err := loadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Server.Port != "8080" || config.Server.Mode != "debug" || !slices.Equal(config.Server.CORSOrigins, []string{"*"}) {
+		t.Errorf("服务配置 = %+v", config.Server)
+	}
+	db := config.DB
+	if db.Driver != DriverMySQL || db.Port != "3306" || db.User != "root" || db.Database != "table_design" ||
+		db.MaxOpenConns != 100 || db.MaxIdleConns != 10 {
+		t.Errorf("数据库配置 = %+v", db)
+	}
+	if config.Auth.AccessTTL != 15*time.Minute || config.Log.Level != LogLevelInfo {
+		t.Errorf("认证、日志配置 = %+v %+v", config.Auth, config.Log)
+	}
+
+	// 驱动相关的默认值
+	t.Setenv("DB_DRIVER", DriverPostgres)
+	if config, err = loadConfig(""); err != nil {
+		t.Fatal(err)
+	}
+	if config.DB.Port != "5432" || config.DB.User != "postgres" || config.DB.SSLMode != "disable" {
+		t.Errorf("PostgreSQL 配置 = %+v", config.DB)
+	}
+}
+
+func TestConfigExample(t *testing.T) {
+	example, err := filepath.Abs("config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useConfigEnv(t)
+	t.Setenv("DB_PASSWORD", "secret")
+
+	config, err := loadConfig(example)
+	if err != nil {
+		t.Fatalf("config.example.yaml 无效: %v", err)
+	}
+	defaults := defaultConfig()
+	defaults.DB.Password = "secret"
+	defaults.applyDriverDefaults()
+	if !reflect.DeepEqual(*config, defaults) {
+		t.Errorf("config.example.yaml 与默认配置不一致:\n%+v\n%+v", *config, defaults)
+	}
+}
+
+func TestLoadConfigFileAndEnv(t *testing.T) {
+	useConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+server:
+  port: 9090
+  mode: release
+  cors_origins: ["https://shop.example.com", "http://localhost:5173"]
+database:
+  driver: postgres
+  host: db.internal
+  user: app
+  password: file-password
+  max_open_conns: 50
+  conn_max_lifetime: 30m
+auth:
+  jwt_secret: 0123456789abcdef0123456789abcdef
+log:
+  level: warn
+`)
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	config, err := loadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Server.Port != "9090" || config.Server.Mode != "release" {
+		t.Errorf("服务配置 = %+v", config.Server)
+	}
+	if !slices.Equal(config.Server.CORSOrigins, []string{"https://a.example.com", "https://b.example.com"}) {
+		t.Errorf("CORS_ORIGINS 未覆盖配置文件: %v", config.Server.CORSOrigins)
+	}
+	db := config.DB
+	if db.Host != "db.internal" || db.User != "app" || db.Password != "file-password" || db.Port != "5432" ||
+		db.MaxOpenConns != 20 || db.MaxIdleConns != 10 || db.ConnMaxLifetime != 30*time.Minute {
+		t.Errorf("数据库配置 = %+v", db)
+	}
+	if config.Log.Level != LogLevelDebug {
+		t.Errorf("LOG_LEVEL 未覆盖配置文件: %s", config.Log.Level)
+	}
+}
+
+func TestLoadConfigListsEveryProblem(t *testing.T) {
+	useConfigEnv(t)
+	writeConfigFile(t, "bad.yaml", `
+server:
+  port: 70000
+  mode: production
+  cors_origins: ["example.com"]
+database:
+  driver: postgres
+  password: do-not-print-me
+  sslmode: verify-full
+  max_open_conns: 5
+  max_idle_conns: 10
+auth:
+  jwt_secret: short
+orders:
+  pending_ttl: 30m
+  expiry_interval: 0s
+log:
+  level: verbose
+tracing:
+  exporter: otlp
+  sample_ratio: 2
+`)
+	t.Setenv("DB_CONN_MAX_LIFETIME", "forever")
+
+	problems := configProblems(t, "bad.yaml")
+	message := (&ConfigError{Problems: problems}).Error()
+	for _, want := range []string{
+		"server.port（PORT）", "server.mode（GIN_MODE）", "server.cors_origins（CORS_ORIGINS）",
+		"database.sslrootcert（DB_SSLROOTCERT）", "database.max_idle_conns（DB_MAX_IDLE_CONNS）",
+		"auth.jwt_secret（JWT_SECRET）", "orders.expiry_interval（ORDER_EXPIRY_INTERVAL）", "log.level（LOG_LEVEL）", "环境变量 DB_CONN_MAX_LIFETIME",
+		"tracing.endpoint（OTEL_EXPORTER_OTLP_ENDPOINT）", "tracing.sample_ratio（OTEL_TRACES_SAMPLER_ARG）",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("错误信息中缺少 %s:\n%s", want, message)
+		}
+	}
+	if strings.Contains(message, "do-not-print-me") {
+		t.Errorf("错误信息中包含数据库密码:\n%s", message)
+	}
+
+	// MySQL 没有设置密码；release 模式必须设置 JWT 密钥，也不能开启 AutoMigrate
+	useConfigEnv(t)
+	t.Setenv("GIN_MODE", "release")
+	t.Setenv("DB_AUTO_MIGRATE", "true")
+	problems = configProblems(t, "")
+	if len(problems) != 3 {
+		t.Errorf("问题数 = %d，期望 3: %v", len(problems), problems)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	useConfigEnv(t)
+
+	// 显式指定的配置文件必须存在
+	if _, err := loadConfig(filepath.Join("conf", "missing.yaml")); err == nil {
+		t.Error("配置文件不存在时应返回错误")
+	}
+	t.Setenv("CONFIG_FILE", "missing.yaml")
+	if _, err := loadConfig(""); err == nil {
+		t.Error("CONFIG_FILE 指定的文件不存在时应返回错误")
+	}
+
+	// 拼写错误的配置项不会被静默忽略
+	writeConfigFile(t, "typo.yaml", "database:\n  pasword: secret\n")
+	if _, err := loadConfig("typo.yaml"); err == nil || !strings.Contains(err.Error(), "pasword") {
+		t.Errorf("未知配置项: %v", err)
+	}
+	writeConfigFile(t, "types.yaml", "auth:\n  access_ttl: 15\n")
+	if _, err := loadConfig("types.yaml"); err == nil {
+		t.Error("类型错误的配置项应返回错误")
+	}
+}
+
+// TestLoadConfigFileErrorHidesSource 解析失败时的错误不包含配置文件的内容，避免密码出现在启动日志中
+func TestLoadConfigFileErrorHidesSource(t *testing.T) {
+	useConfigEnv(t)
+	const password = "s3cr3t-password"
+
+	files := map[string]string{
+		"unknown.yaml": "database:\n  user: root\n  password: " + password + "\n  pasword: typo\n",
+		"types.yaml":   "database:\n  password: " + password + "\n  port: [3306]\n",
+		"syntax.yaml":  "database:\n  password: " + password + "\n  host: \"localhost\n",
+	}
+	for name, content := range files {
+		writeConfigFile(t, name, content)
+		_, err := loadConfig(name)
+		if err == nil {
+			t.Errorf("%s: 期望返回错误", name)
+			continue
+		}
+		if strings.Contains(err.Error(), password) {
+			t.Errorf("%s: 错误信息包含了密码: %v", name, err)
+		}
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("%s: 错误信息应包含文件名: %v", name, err)
+		}
+	}
+}
@@ -1,27 +1,66 @@
 package main
 
 import (
+	"database/sql"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// db 全局数据库实例
-var db *gorm.DB
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// PostgreSQL 支持的 sslmode，含义见 https://www.postgresql.org/docs/current/libpq-ssl.html
+var postgresSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
 
-// DBConfig 数据库配置结构体
+// sqliteMemory SQLite 内存数据库，进程退出后数据丢失
+const sqliteMemory = ":memory:"
+
+// DBConfig 数据库配置结构体，通过配置文件的 database 部分或 DB_* 环境变量设置，见 config.go
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Database string
+	Driver   string `yaml:"driver"` // mysql（默认）、postgres 或 sqlite
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"` // 没有默认值，MySQL/PostgreSQL 必须设置；不会输出到日志
+	Database string `yaml:"name"`     // MySQL/PostgreSQL 为数据库名，SQLite 为数据库文件路径（:memory: 表示内存数据库）
+
+	// PostgreSQL SSL 配置
+	SSLMode     string `yaml:"sslmode"`     // disable、require、verify-full 等，默认 disable
+	SSLRootCert string `yaml:"sslrootcert"` // verify-ca / verify-full 时用于校验服务端证书的 CA 证书路径
+
+	// 连接池配置（SQLite 固定使用一个连接）
+	MaxOpenConns    int           `yaml:"max_open_conns"`     // 最大打开连接数，默认 100，0 表示不限制
+	MaxIdleConns    int           `yaml:"max_idle_conns"`     // 最大空闲连接数，默认 10
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`  // 连接可复用的最大时间，0 表示不限制
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"` // 连接最大空闲时间，0 表示不限制
 }
 
 // connectDB 连接数据库并返回 GORM 实例
 func connectDB(config DBConfig) (*gorm.DB, error) {
+	switch config.Driver {
+	case "", DriverMySQL:
+		return connectMySQL(config)
+	case DriverPostgres:
+		return connectPostgres(config)
+	case DriverSQLite:
+		return connectSQLite(config)
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s（可选 %s、%s、%s）", config.Driver, DriverMySQL, DriverPostgres, DriverSQLite)
+	}
+}
+
+// connectMySQL 连接 MySQL
+func connectMySQL(config DBConfig) (*gorm.DB, error) {
 	// 构建 DSN (Data Source Name)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		config.User, config.Password, config.Host, config.Port, config.Database)
@@ -29,7 +68,8 @@ func connectDB(config DBConfig) (*gorm.DB, error) {
 	fmt.Printf("正在连接数据库: %s@%s:%s/%s\n", config.User, config.Host, config.Port, config.Database)
 
 	// 连接数据库
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// TranslateError 将唯一索引冲突等数据库错误转换为 gorm.ErrDuplicatedKey 等通用错误
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("连接数据库失败: %v\n\n常见问题排查:\n"+
 			"1. 检查数据库服务是否已启动\n"+
@@ -40,30 +80,149 @@ func connectDB(config DBConfig) (*gorm.DB, error) {
 			"6. 检查 MySQL 用户是否允许从当前 IP 连接", err, config.Database)
 	}
 
-	// 测试数据库连接
+	if err := pingAndConfigurePool(db, config); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// connectPostgres 连接 PostgreSQL
+func connectPostgres(config DBConfig) (*gorm.DB, error) {
+	dsn, err := postgresDSN(config)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("正在连接数据库: postgres %s@%s:%s/%s (sslmode=%s)\n",
+		config.User, config.Host, config.Port, config.Database, postgresSSLMode(config))
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %v\n\n常见问题排查:\n"+
+			"1. 检查数据库服务是否已启动\n"+
+			"2. 检查用户名和密码是否正确\n"+
+			"3. 检查数据库是否存在: %s\n"+
+			"4. 检查 pg_hba.conf 是否允许从当前 IP 连接\n"+
+			"5. 服务端要求 SSL 时设置 DB_SSLMODE=require", err, config.Database)
+	}
+
+	if err := pingAndConfigurePool(db, config); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// postgresDSN 构建 PostgreSQL 的 key=value 格式 DSN
+// 值中的单引号和反斜杠需要转义，密码可以包含空格等特殊字符
+func postgresDSN(config DBConfig) (string, error) {
+	sslMode := postgresSSLMode(config)
+	valid := false
+	for _, mode := range postgresSSLModes {
+		if sslMode == mode {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("无效的 sslmode: %s（可选 %s）", sslMode, strings.Join(postgresSSLModes, "、"))
+	}
+	if (sslMode == "verify-ca" || sslMode == "verify-full") && config.SSLRootCert == "" {
+		return "", fmt.Errorf("sslmode=%s 时必须设置 CA 证书路径（DB_SSLROOTCERT）", sslMode)
+	}
+
+	params := [][2]string{
+		{"host", config.Host},
+		{"port", config.Port},
+		{"user", config.User},
+		{"password", config.Password},
+		{"dbname", config.Database},
+		{"sslmode", sslMode},
+		{"sslrootcert", config.SSLRootCert},
+	}
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	var parts []string
+	for _, param := range params {
+		if param[1] == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s='%s'", param[0], quote.Replace(param[1])))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// postgresSSLMode 返回 sslmode，未设置时为 disable
+func postgresSSLMode(config DBConfig) string {
+	if config.SSLMode == "" {
+		return "disable"
+	}
+	return config.SSLMode
+}
+
+// pingAndConfigurePool 测试数据库连接并设置连接池参数
+func pingAndConfigurePool(db *gorm.DB, config DBConfig) error {
 	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("获取数据库实例失败: %v", err)
+		return fmt.Errorf("获取数据库实例失败: %v", err)
 	}
 
 	// Ping 测试连接
 	if err := sqlDB.Ping(); err != nil {
-		return nil, fmt.Errorf("数据库连接测试失败: %v", err)
+		return fmt.Errorf("数据库连接测试失败: %v", err)
 	}
 
-	// 设置连接池参数
-	sqlDB.SetMaxIdleConns(10)   // 设置空闲连接池中连接的最大数量
-	sqlDB.SetMaxOpenConns(100)  // 设置打开数据库连接的最大数量
-	sqlDB.SetConnMaxLifetime(0) // 设置连接可复用的最大时间（0 表示不限制）
+	configurePool(sqlDB, config)
+	return nil
+}
 
-	return db, nil
+// configurePool 设置连接池参数
+func configurePool(sqlDB *sql.DB, config DBConfig) {
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)       // 设置打开数据库连接的最大数量（0 表示不限制）
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)       // 设置空闲连接池中连接的最大数量
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime) // 设置连接可复用的最大时间（0 表示不限制）
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime) // 设置连接最大空闲时间（0 表示不限制）
 }
 
-// getEnv 获取环境变量，如果不存在则返回默认值
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// connectSQLite 连接 SQLite，用于本地开发和测试，不需要单独的数据库服务
+// 使用纯 Go 实现的驱动，不依赖 CGO
+func connectSQLite(config DBConfig) (*gorm.DB, error) {
+	path := config.Database
+	if path == "" {
+		path = sqliteMemory
+	}
+	fmt.Printf("正在连接数据库: sqlite %s\n", path)
+
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(path)), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库实例失败: %v", err)
 	}
-	return defaultValue
+
+	// SQLite 同一时间只允许一个写入者，只使用一个连接，避免并发写入时出现 database is locked；
+	// 内存数据库的数据属于连接本身，连接不能被关闭或替换，否则数据会丢失
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("数据库连接测试失败: %v", err)
+	}
+
+	return db, nil
 }
 
+// sqliteDSN 构建 SQLite DSN：开启外键约束（SQLite 默认不检查外键），并设置锁等待时间
+func sqliteDSN(path string) string {
+	pragmas := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	if path == sqliteMemory {
+		return "file::memory:?" + pragmas
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + pragmas
+	}
+	return path + "?" + pragmas
+}
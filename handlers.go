@@ -1,6 +1,9 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -14,28 +17,65 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// GetUsers 查询所有用户
-// GET /users
-func GetUsers(c *gin.Context) {
-	users, err := queryUsers(db)
+// userListSpec 用户列表可排序字段
+var userListSpec = listSpec{
+	SortFields: map[string]sortField{
+		"id":         {Column: "id", Kind: sortKindInt},
+		"created_at": {Column: "created_at", Kind: sortKindTime},
+	},
+	DefaultSort: "id",
+}
+
+// GetUsers 查询用户列表（分页）
+// GET /users?page=1&page_size=20&status=1&start_date=2025-01-01&end_date=2025-12-31&sort=created_at&order=desc
+func (s *Server) GetUsers(c *gin.Context) {
+	params, err := parseListParams(c, userListSpec)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: err.Error(),
-		})
+		respondListError(c, err)
+		return
+	}
+
+	var filter UserFilter
+	filter.Statuses, err = parseStatusParam(c)
+	if err == nil {
+		filter.Created, err = parseTimeRange(c)
+	}
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	result, err := s.users.List(c.Request.Context(), filter, params)
+	if err != nil {
+		respondListError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "查询成功",
-		Data:    users,
+		Data:    result,
+	})
+}
+
+// respondListError 返回列表查询的错误响应，参数错误返回 400，其余返回 500
+func respondListError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidListParams) {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, Response{
+		Code:    500,
+		Message: err.Error(),
 	})
 }
 
 // GetUserOrders 查询指定用户的订单
 // GET /users/:id/orders
-func GetUserOrders(c *gin.Context) {
+func (s *Server) GetUserOrders(c *gin.Context) {
 	userIDStr := c.Param("id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
@@ -45,16 +85,17 @@ func GetUserOrders(c *gin.Context) {
 		})
 		return
 	}
+	if !canAccessUser(currentUser(c), uint(userID)) {
+		respondForbidden(c)
+		return
+	}
 
-	var user User
-	if err := db.Debug().
-		Preload("Orders").
-		Preload("Addresses").
-		Preload("Orders.OrderItems").
-		First(&user, uint(userID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "用户不存在",
+	user, err := s.users.FindWithOrders(c.Request.Context(), uint(userID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
@@ -68,7 +109,7 @@ func GetUserOrders(c *gin.Context) {
 
 // GetUserOrdersWithProducts 查询用户的所有订单及每个订单的商品
 // GET /users/:id/orders/products
-func GetUserOrdersWithProducts(c *gin.Context) {
+func (s *Server) GetUserOrdersWithProducts(c *gin.Context) {
 	userIDStr := c.Param("id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
@@ -78,16 +119,17 @@ func GetUserOrdersWithProducts(c *gin.Context) {
 		})
 		return
 	}
+	if !canAccessUser(currentUser(c), uint(userID)) {
+		respondForbidden(c)
+		return
+	}
 
-	var user User
-	if err := db.Debug().
-		Preload("Orders").
-		Preload("Orders.OrderItems").
-		Preload("Orders.OrderItems.Product").
-		First(&user, uint(userID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "用户不存在",
+	user, err := s.users.FindWithOrderProducts(c.Request.Context(), uint(userID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
@@ -99,9 +141,54 @@ func GetUserOrdersWithProducts(c *gin.Context) {
 	})
 }
 
+// UpdateUserRole 修改用户角色（仅管理员）
+// PUT /users/:id/role
+func (s *Server) UpdateUserRole(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的用户ID",
+		})
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := updateUserRole(c.Request.Context(), s.users, currentUser(c), uint(userID), req.Role)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrInvalidRole), errors.Is(err, ErrChangeOwnRole):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "修改成功",
+		Data:    user,
+	})
+}
+
 // GetProductOrders 查询商品被哪些订单购买
 // GET /products/:id/orders
-func GetProductOrders(c *gin.Context) {
+func (s *Server) GetProductOrders(c *gin.Context) {
 	productIDStr := c.Param("id")
 	productID, err := strconv.ParseUint(productIDStr, 10, 32)
 	if err != nil {
@@ -112,15 +199,12 @@ func GetProductOrders(c *gin.Context) {
 		return
 	}
 
-	var product Product
-	if err := db.Debug().
-		Preload("OrderItems").
-		Preload("OrderItems.Order").
-		Preload("OrderItems.Order.User").
-		First(&product, uint(productID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "商品不存在",
+	product, err := s.products.FindWithOrders(c.Request.Context(), uint(productID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
@@ -134,7 +218,7 @@ func GetProductOrders(c *gin.Context) {
 
 // GetOrderProducts 查询订单包含哪些商品
 // GET /orders/:id/products
-func GetOrderProducts(c *gin.Context) {
+func (s *Server) GetOrderProducts(c *gin.Context) {
 	orderIDStr := c.Param("id")
 	orderID, err := strconv.ParseUint(orderIDStr, 10, 32)
 	if err != nil {
@@ -145,18 +229,19 @@ func GetOrderProducts(c *gin.Context) {
 		return
 	}
 
-	var order Order
-	if err := db.Debug().
-		Preload("OrderItems").
-		Preload("OrderItems.Product").
-		Preload("User").
-		First(&order, uint(orderID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "订单不存在",
+	order, err := s.orders.FindDetail(c.Request.Context(), uint(orderID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
+	if !canViewOrder(currentUser(c), order) {
+		respondForbidden(c)
+		return
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
@@ -166,8 +251,8 @@ func GetOrderProducts(c *gin.Context) {
 }
 
 // GetProductSalesStats 统计商品的销售情况
-// GET /products/:id/stats
-func GetProductSalesStats(c *gin.Context) {
+// GET /products/:id/stats?status=1,2,3&start_date=2026-10-01&end_date=2026-10-31&interval=day
+func (s *Server) GetProductSalesStats(c *gin.Context) {
 	productIDStr := c.Param("id")
 	productID, err := strconv.ParseUint(productIDStr, 10, 32)
 	if err != nil {
@@ -178,74 +263,116 @@ func GetProductSalesStats(c *gin.Context) {
 		return
 	}
 
-	var product Product
-	if err := db.Debug().
-		Preload("OrderItems").
-		Preload("OrderItems.Order").
-		First(&product, uint(productID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "商品不存在",
+	filter, err := parseSalesStatsFilter(c)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	stats, err := s.products.SalesStats(c.Request.Context(), uint(productID), filter)
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
 
-	// 统计销售数据
-	totalQuantity := 0
-	totalAmount := 0.0
-	orderCount := 0
-	orderMap := make(map[uint]bool)
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    stats,
+	})
+}
 
-	for _, item := range product.OrderItems {
-		totalQuantity += item.Quantity
-		totalAmount += item.Subtotal
-		if !orderMap[item.OrderID] {
-			orderMap[item.OrderID] = true
-			orderCount++
+// parseSalesStatsFilter 解析销售统计条件：status、start_date/end_date、interval
+// 已取消的订单单独统计为退款和取消数量，不能作为计入销量的状态，否则会被重复统计
+func parseSalesStatsFilter(c *gin.Context) (SalesStatsFilter, error) {
+	var filter SalesStatsFilter
+	var err error
+	if filter.Statuses, err = parseStatusParam(c); err != nil {
+		return filter, err
+	}
+	for _, status := range filter.Statuses {
+		if _, ok := orderStatusText[status]; !ok {
+			return filter, fmt.Errorf("%w: 未知的订单状态 %d", ErrInvalidListParams, status)
+		}
+		if status == OrderStatusCancelled {
+			return filter, fmt.Errorf("%w: 已取消的订单不计入销量，已单独统计在 refunded_* 和 cancelled_quantity 中", ErrInvalidListParams)
 		}
 	}
+	if filter.Created, err = parseTimeRange(c); err != nil {
+		return filter, err
+	}
+	switch filter.Interval = c.Query("interval"); filter.Interval {
+	case "", SalesIntervalDay, SalesIntervalWeek, SalesIntervalMonth:
+	default:
+		return filter, fmt.Errorf("%w: interval 必须是 day、week 或 month", ErrInvalidListParams)
+	}
+	return filter, nil
+}
+
+// productListSpec 商品列表可排序字段
+var productListSpec = listSpec{
+	SortFields: map[string]sortField{
+		"id":         {Column: "id", Kind: sortKindInt},
+		"sort":       {Column: "sort", Kind: sortKindInt},
+		"price":      {Column: "price", Kind: sortKindMoney},
+		"sales":      {Column: "sales", Kind: sortKindInt},
+		"created_at": {Column: "created_at", Kind: sortKindTime},
+	},
+	DefaultSort: "sort",
+}
 
-	stats := map[string]interface{}{
-		"product":        product,
-		"total_quantity": totalQuantity,
-		"total_amount":    totalAmount,
-		"order_count":    orderCount,
-		"average_amount": 0.0,
+// parseProductFilter 解析商品列表过滤条件：status、category_id、min_price/max_price、start_date/end_date
+func parseProductFilter(c *gin.Context) (ProductFilter, error) {
+	var filter ProductFilter
+	var err error
+	if filter.Statuses, err = parseStatusParam(c); err != nil {
+		return filter, err
 	}
+	if filter.CategoryID, err = parseUintParam(c, "category_id"); err != nil {
+		return filter, err
+	}
+	if filter.Price, err = parseMoneyRange(c, "min_price", "max_price"); err != nil {
+		return filter, err
+	}
+	filter.Created, err = parseTimeRange(c)
+	return filter, err
+}
 
-	if orderCount > 0 {
-		stats["average_amount"] = totalAmount / float64(orderCount)
+// GetProducts 查询商品列表（分页）
+// GET /products?page=1&page_size=20&status=1&min_price=100&max_price=9999&sort=price&order=desc
+func (s *Server) GetProducts(c *gin.Context) {
+	params, err := parseListParams(c, productListSpec)
+	if err != nil {
+		respondListError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, Response{
-		Code:    200,
-		Message: "查询成功",
-		Data:    stats,
-	})
-}
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
 
-// GetProducts 查询所有商品
-// GET /products
-func GetProducts(c *gin.Context) {
-	var products []Product
-	if err := db.Debug().Find(&products).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: err.Error(),
-		})
+	result, err := s.products.List(c.Request.Context(), filter, params)
+	if err != nil {
+		respondListError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "查询成功",
-		Data:    products,
+		Data:    result,
 	})
 }
 
 // GetProduct 查询单个商品
 // GET /products/:id
-func GetProduct(c *gin.Context) {
+func (s *Server) GetProduct(c *gin.Context) {
 	productIDStr := c.Param("id")
 	productID, err := strconv.ParseUint(productIDStr, 10, 32)
 	if err != nil {
@@ -256,11 +383,12 @@ func GetProduct(c *gin.Context) {
 		return
 	}
 
-	var product Product
-	if err := db.Debug().First(&product, uint(productID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "商品不存在",
+	product, err := s.products.FindByID(c.Request.Context(), uint(productID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
@@ -272,31 +400,65 @@ func GetProduct(c *gin.Context) {
 	})
 }
 
-// GetOrders 查询所有订单
-// GET /orders
-func GetOrders(c *gin.Context) {
-	var orders []Order
-	if err := db.Debug().
-		Preload("User").
-		Preload("OrderItems").
-		Find(&orders).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: err.Error(),
-		})
+// orderListSpec 订单列表可排序字段
+var orderListSpec = listSpec{
+	SortFields: map[string]sortField{
+		"id":           {Column: "id", Kind: sortKindInt},
+		"created_at":   {Column: "created_at", Kind: sortKindTime},
+		"pay_amount":   {Column: "pay_amount", Kind: sortKindMoney},
+		"total_amount": {Column: "total_amount", Kind: sortKindMoney},
+	},
+	DefaultSort: "created_at",
+	DefaultDesc: true,
+}
+
+// GetOrders 查询订单列表（分页）
+// GET /orders?page=1&page_size=20&status=0,1&user_id=1&start_date=2025-01-01&end_date=2025-01-31
+func (s *Server) GetOrders(c *gin.Context) {
+	params, err := parseListParams(c, orderListSpec)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	var filter OrderFilter
+	filter.Statuses, err = parseStatusParam(c)
+	if err == nil {
+		filter.UserID, err = parseUintParam(c, "user_id")
+	}
+	if err == nil {
+		filter.Created, err = parseTimeRange(c)
+	}
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	// 顾客只能查看自己的订单
+	if actor := currentUser(c); !canListAllOrders(actor) {
+		if filter.UserID != nil && *filter.UserID != actor.ID {
+			respondForbidden(c)
+			return
+		}
+		filter.UserID = &actor.ID
+	}
+
+	result, err := s.orders.List(c.Request.Context(), filter, params)
+	if err != nil {
+		respondListError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
 		Message: "查询成功",
-		Data:    orders,
+		Data:    result,
 	})
 }
 
 // GetOrder 查询单个订单
 // GET /orders/:id
-func GetOrder(c *gin.Context) {
+func (s *Server) GetOrder(c *gin.Context) {
 	orderIDStr := c.Param("id")
 	orderID, err := strconv.ParseUint(orderIDStr, 10, 32)
 	if err != nil {
@@ -307,19 +469,19 @@ func GetOrder(c *gin.Context) {
 		return
 	}
 
-	var order Order
-	if err := db.Debug().
-		Preload("User").
-		Preload("Address").
-		Preload("OrderItems").
-		Preload("OrderItems.Product").
-		First(&order, uint(orderID)).Error; err != nil {
-		c.JSON(http.StatusNotFound, Response{
-			Code:    404,
-			Message: "订单不存在",
+	order, err := s.orders.FindDetail(c.Request.Context(), uint(orderID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
 		})
 		return
 	}
+	if !canViewOrder(currentUser(c), order) {
+		respondForbidden(c)
+		return
+	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
@@ -328,20 +490,186 @@ func GetOrder(c *gin.Context) {
 	})
 }
 
-// SeedData 插入测试数据接口
-// POST /seed
-func SeedData(c *gin.Context) {
-	if err := seedData(); err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
+// CreateOrder 创建订单
+// POST /orders
+func (s *Server) CreateOrder(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	// 未指定下单用户时为当前用户下单
+	actor := currentUser(c)
+	if req.UserID == 0 {
+		req.UserID = actor.ID
+	}
+	if !canPlaceOrder(actor, req.UserID) {
+		respondForbidden(c)
+		return
+	}
+	req.OperatorID = actor.ID
+
+	order, err := s.orders.Place(c.Request.Context(), req)
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	s.metrics.OrderCreated()
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "下单成功",
+		Data:    order,
+	})
+}
+
+// orderErrorStatus 将订单业务错误映射为 HTTP 状态码
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUserNotFound),
+		errors.Is(err, ErrAddressNotFound),
+		errors.Is(err, ErrProductNotFound),
+		errors.Is(err, ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrAddressNotOwned),
+		errors.Is(err, ErrProductOffSale),
+		errors.Is(err, ErrInvalidStockAdjustment):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInsufficientStock),
+		errors.Is(err, ErrIllegalTransition),
+		errors.Is(err, ErrStockBelowReserved):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// PayOrderRequest 支付订单请求参数
+type PayOrderRequest struct {
+	PayMethod string `json:"pay_method" binding:"max=20"`
+}
+
+// PayOrder 支付订单
+// POST /orders/:id/pay
+func (s *Server) PayOrder(c *gin.Context) {
+	var req PayOrderRequest
+	// 请求体可选，未传时不修改支付方式
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+	s.handleOrderTransition(c, OrderEventPay, req.PayMethod)
+}
+
+// ShipOrder 订单发货
+// POST /orders/:id/ship
+func (s *Server) ShipOrder(c *gin.Context) {
+	s.handleOrderTransition(c, OrderEventShip, "")
+}
+
+// CompleteOrder 订单确认收货
+// POST /orders/:id/complete
+func (s *Server) CompleteOrder(c *gin.Context) {
+	s.handleOrderTransition(c, OrderEventComplete, "")
+}
+
+// CancelOrder 取消订单
+// POST /orders/:id/cancel
+func (s *Server) CancelOrder(c *gin.Context) {
+	s.handleOrderTransition(c, OrderEventCancel, "")
+}
+
+// handleOrderTransition 订单状态流转接口的公共处理逻辑
+func (s *Server) handleOrderTransition(c *gin.Context, event OrderEvent, payMethod string) {
+	orderIDStr := c.Param("id")
+	orderID, err := strconv.ParseUint(orderIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的订单ID",
+		})
+		return
+	}
+
+	current, err := s.orders.FindByID(c.Request.Context(), uint(orderID))
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+	actor := currentUser(c)
+	if !canTransitionOrder(actor, current, event) {
+		respondForbidden(c)
+		return
+	}
+
+	order, err := s.orders.Transition(c.Request.Context(), uint(orderID), event, payMethod, actor.ID)
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	s.metrics.OrderTransitioned(event)
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "订单状态已更新为" + orderStatusText[order.Status],
+		Data:    order,
+	})
+}
+
+// SeedData 生成测试数据接口
+// POST /seed?users=20&addresses=3&products=40&orders=200&seed=1&reset=false
+func (s *Server) SeedData(c *gin.Context) {
+	var opts SeedOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	result, err := s.seed(opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrInvalidSeedOptions) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, Response{
+			Code:    status,
 			Message: err.Error(),
 		})
 		return
 	}
 
+	message := "测试数据插入成功"
+	if result.Skipped {
+		message = "数据库中已有数据，跳过插入（使用 reset=true 清空后重新生成）"
+	}
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "测试数据插入成功",
+		Message: message,
+		Data:    result,
 	})
 }
 
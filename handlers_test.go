@@ -0,0 +1,663 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSeedData(t *testing.T) {
+	ts := newEmptyTestServer(t)
+	createTestUser(t, ts.db, "admin", RoleAdmin)
+	createTestUser(t, ts.db, "customer", RoleCustomer)
+	admin := ts.login("admin")
+
+	ts.expect(http.MethodPost, "/seed", ts.login("customer"), nil, http.StatusForbidden)
+
+	resp := ts.expect(http.MethodPost, "/seed?users=5&products=8&orders=20&seed=7", admin, nil, http.StatusOK)
+	var result SeedResult
+	resp.decode(t, &result)
+	if result.Skipped || result.Users != 5 || result.Products != 8 || result.Orders == 0 || len(result.Accounts) != 5 {
+		t.Fatalf("生成结果 = %+v", result)
+	}
+	// 生成的用户可以用统一的密码登录
+	ts.login(result.Accounts[0])
+
+	resp = ts.expect(http.MethodGet, "/products", "", nil, http.StatusOK)
+	var page pageResult[Product]
+	resp.decode(t, &page)
+	var onSale int64
+	ts.db.Model(&Product{}).Where("status = ?", ProductStatusOnSale).Count(&onSale)
+	if page.Total != onSale {
+		t.Errorf("商品列表总数 = %d，上架商品 = %d", page.Total, onSale)
+	}
+
+	// 已有数据时重复调用不报错，也不插入数据
+	resp = ts.expect(http.MethodPost, "/seed", admin, nil, http.StatusOK)
+	resp.decode(t, &result)
+	if !result.Skipped {
+		t.Errorf("重复生成没有跳过: %+v", result)
+	}
+
+	// reset 清空后重新生成，管理员账号保留，之前的顾客被删除
+	resp = ts.expect(http.MethodPost, "/seed?users=3&products=4&orders=5&reset=true", admin, nil, http.StatusOK)
+	resp.decode(t, &result)
+	if result.Skipped || result.Users != 3 {
+		t.Errorf("重置后的生成结果 = %+v", result)
+	}
+	var users, products int64
+	ts.db.Model(&User{}).Count(&users)
+	ts.db.Model(&Product{}).Count(&products)
+	if users != 4 || products != 4 {
+		t.Errorf("重置后用户 %d 个、商品 %d 个，期望 4、4", users, products)
+	}
+	ts.expect(http.MethodGet, "/auth/me", admin, nil, http.StatusOK)
+
+	for _, query := range []string{"users=-1", "orders=1000000", "addresses=9", "users=abc", "reset=maybe"} {
+		ts.expect(http.MethodPost, "/seed?"+query, admin, nil, http.StatusBadRequest)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.login("admin")
+
+	resp := ts.expect(http.MethodGet, "/users?page_size=2", admin, nil, http.StatusOK)
+	var page pageResult[User]
+	resp.decode(t, &page)
+	if page.Total != 5 || len(page.Items) != 2 || !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("第一页 = %+v", page)
+	}
+	if page.Items[0].ID != fixtureZhangsanID || page.Items[1].ID != fixtureLisiID {
+		t.Errorf("默认按 id 升序，实际 %d、%d", page.Items[0].ID, page.Items[1].ID)
+	}
+
+	// 游标分页从上一页的最后一条之后继续
+	resp = ts.expect(http.MethodGet, "/users?page_size=2&cursor="+page.NextCursor, admin, nil, http.StatusOK)
+	var next pageResult[User]
+	resp.decode(t, &next)
+	if len(next.Items) != 2 || next.Items[0].ID != fixtureWangwuID {
+		t.Errorf("第二页 = %+v", next)
+	}
+
+	ts.db.Model(&User{}).Where("id = ?", fixtureWangwuID).Update("status", UserStatusBanned)
+	resp = ts.expect(http.MethodGet, fmt.Sprintf("/users?status=%d", UserStatusBanned), admin, nil, http.StatusOK)
+	var banned pageResult[User]
+	resp.decode(t, &banned)
+	if banned.Total != 1 || banned.Items[0].ID != fixtureWangwuID {
+		t.Errorf("按状态过滤 = %+v", banned)
+	}
+
+	for _, query := range []string{"page=0", "page_size=1000", "sort=password", "order=up", "status=x", "start_date=yesterday"} {
+		ts.expect(http.MethodGet, "/users?"+query, admin, nil, http.StatusBadRequest)
+	}
+
+	ts.expect(http.MethodGet, "/users", ts.login("operator"), nil, http.StatusForbidden)
+	ts.expect(http.MethodGet, "/users", ts.login("zhangsan"), nil, http.StatusForbidden)
+}
+
+func TestGetUserOrders(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	resp := ts.expect(http.MethodGet, "/users/1/orders", zhangsan, nil, http.StatusOK)
+	var user User
+	resp.decode(t, &user)
+	if user.ID != fixtureZhangsanID || len(user.Orders) != 2 || len(user.Addresses) != 2 {
+		t.Fatalf("用户订单 = %d 个订单，%d 个地址", len(user.Orders), len(user.Addresses))
+	}
+	for _, order := range user.Orders {
+		if len(order.OrderItems) == 0 {
+			t.Errorf("订单 %d 没有加载订单明细", order.ID)
+		}
+	}
+
+	// 顾客不能查看其他用户的订单，运营可以
+	ts.expect(http.MethodGet, "/users/2/orders", zhangsan, nil, http.StatusForbidden)
+	ts.expect(http.MethodGet, "/users/2/orders", ts.login("operator"), nil, http.StatusOK)
+}
+
+func TestGetUserOrdersWithProducts(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/users/2/orders/products", ts.login("lisi"), nil, http.StatusOK)
+	var user User
+	resp.decode(t, &user)
+	if len(user.Orders) != 1 || len(user.Orders[0].OrderItems) != 1 {
+		t.Fatalf("李四的订单 = %+v", user.Orders)
+	}
+	if item := user.Orders[0].OrderItems[0]; item.ProductID != fixtureMacBookID || item.ProductName == "" {
+		t.Errorf("订单明细 = %+v", item)
+	}
+
+	ts.expect(http.MethodGet, "/users/1/orders/products", ts.login("lisi"), nil, http.StatusForbidden)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.login("admin")
+
+	resp := ts.expect(http.MethodPut, "/users/3/role", admin, UpdateUserRoleRequest{Role: RoleOperator}, http.StatusOK)
+	var user User
+	resp.decode(t, &user)
+	if user.ID != fixtureWangwuID || user.Role != RoleOperator {
+		t.Errorf("修改后的用户 = %+v", user)
+	}
+	// 新角色立即生效
+	ts.expect(http.MethodGet, "/products/1/stats", ts.login("wangwu"), nil, http.StatusOK)
+
+	resp = ts.expect(http.MethodPut, "/users/3/role", admin, UpdateUserRoleRequest{Role: "root"}, http.StatusBadRequest)
+	if resp.Message != ErrInvalidRole.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+	resp = ts.expect(http.MethodPut, "/users/4/role", admin, UpdateUserRoleRequest{Role: RoleCustomer}, http.StatusBadRequest)
+	if resp.Message != ErrChangeOwnRole.Error() {
+		t.Errorf("message = %q", resp.Message)
+	}
+	ts.expect(http.MethodPut, "/users/3/role", admin, nil, http.StatusBadRequest)
+	ts.expect(http.MethodPut, "/users/1/role", ts.login("operator"), UpdateUserRoleRequest{Role: RoleAdmin}, http.StatusForbidden)
+}
+
+func TestGetProducts(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/products", "", nil, http.StatusOK)
+	var page pageResult[Product]
+	resp.decode(t, &page)
+	if page.Total != 4 || len(page.Items) != 4 || page.Page != 1 || page.PageSize != defaultPageSize {
+		t.Fatalf("商品列表 = %+v", page)
+	}
+
+	cases := []struct {
+		query string
+		ids   []uint
+	}{
+		{"category_id=3", []uint{fixtureIPhoneID}},
+		{"min_price=1000&max_price=8000&sort=price", []uint{fixtureAirPodsID, fixtureIPhoneID}},
+		{"sort=price&order=desc", []uint{fixtureMacBookID, fixtureIPhoneID, fixtureAirPodsID, fixtureCaseID}},
+		{"max_price=99.00", []uint{fixtureCaseID}},
+	}
+	for _, tc := range cases {
+		resp := ts.expect(http.MethodGet, "/products?"+tc.query, "", nil, http.StatusOK)
+		var page pageResult[Product]
+		resp.decode(t, &page)
+		if !sameIDs(productIDs(page.Items), tc.ids) {
+			t.Errorf("%s: 商品 = %v，期望 %v", tc.query, productIDs(page.Items), tc.ids)
+		}
+	}
+
+	for _, query := range []string{"min_price=abc", "category_id=x", "sort=name", "cursor=bad"} {
+		ts.expect(http.MethodGet, "/products?"+query, "", nil, http.StatusBadRequest)
+	}
+}
+
+// TestProductCursorPagination 排序值相同的商品跨页时按 id 区分，逐页翻完不重复也不遗漏
+func TestProductCursorPagination(t *testing.T) {
+	ts := newTestServer(t)
+
+	// 再创建 6 个价格相同的商品，所有商品的创建时间相同（带微秒，验证游标中的时间精度）
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 123456000, time.Local)
+	for i := 0; i < 6; i++ {
+		product := Product{
+			ProductNo: generateProductNo(100 + i),
+			Name:      fmt.Sprintf("数据线 %d", i),
+			Price:     Yuan(1899),
+			Stock:     10,
+			Status:    ProductStatusOnSale,
+		}
+		if err := ts.db.Create(&product).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts.db.Model(&Product{}).Where("1 = 1").Update("created_at", createdAt)
+
+	var all []Product
+	ts.db.Order("id").Find(&all)
+	if len(all) != 10 {
+		t.Fatalf("商品 %d 个，期望 10 个", len(all))
+	}
+
+	// listAll 按游标逐页查询，返回所有页的商品 ID
+	listAll := func(query string) []uint {
+		t.Helper()
+		var ids []uint
+		path := "/products?page_size=3&" + query
+		for pages := 0; ; pages++ {
+			if pages > len(all) {
+				t.Fatalf("%s: 翻页没有结束", query)
+			}
+			var page pageResult[Product]
+			ts.expect(http.MethodGet, path, "", nil, http.StatusOK).decode(t, &page)
+			ids = append(ids, productIDs(page.Items)...)
+			if !page.HasMore {
+				return ids
+			}
+			path = "/products?page_size=3&" + query + "&cursor=" + page.NextCursor
+		}
+	}
+
+	// 价格降序，价格相同时按 id 降序
+	byPrice := slices.Clone(all)
+	slices.SortStableFunc(byPrice, func(a, b Product) int {
+		if c := cmp.Compare(b.Price.Fen(), a.Price.Fen()); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if got, want := listAll("sort=price&order=desc"), productIDs(byPrice); !sameIDs(got, want) {
+		t.Errorf("按价格降序翻页 = %v，期望 %v", got, want)
+	}
+
+	// 创建时间全部相同，按 id 升序
+	if got, want := listAll("sort=created_at"), productIDs(all); !sameIDs(got, want) {
+		t.Errorf("按创建时间翻页 = %v，期望 %v", got, want)
+	}
+	slices.Reverse(all)
+	if got, want := listAll("sort=created_at&order=desc"), productIDs(all); !sameIDs(got, want) {
+		t.Errorf("按创建时间降序翻页 = %v，期望 %v", got, want)
+	}
+
+	// 游标的排序字段必须与本次请求一致
+	var page pageResult[Product]
+	ts.expect(http.MethodGet, "/products?page_size=3&sort=price", "", nil, http.StatusOK).decode(t, &page)
+	ts.expect(http.MethodGet, "/products?sort=created_at&cursor="+page.NextCursor, "", nil, http.StatusBadRequest)
+}
+
+func TestGetProduct(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/products/1", "", nil, http.StatusOK)
+	var product map[string]interface{}
+	resp.decode(t, &product)
+	// 金额以固定两位小数的字符串输出
+	if product["name"] != "iPhone 15 Pro" || product["price"] != "7999.00" {
+		t.Errorf("商品 = %v", product)
+	}
+}
+
+func TestGetProductOrders(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/products/2/orders", ts.login("operator"), nil, http.StatusOK)
+	var product Product
+	resp.decode(t, &product)
+	if len(product.OrderItems) != 1 {
+		t.Fatalf("AirPods 的订单明细 = %d 条，期望 1", len(product.OrderItems))
+	}
+	if item := product.OrderItems[0]; item.OrderID != fixturePendingOrderID || item.Quantity != 1 {
+		t.Errorf("订单明细 = %+v", item)
+	}
+
+	ts.expect(http.MethodGet, "/products/2/orders", ts.login("zhangsan"), nil, http.StatusForbidden)
+}
+
+func TestGetProductSalesStats(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp := ts.expect(http.MethodGet, "/products/3/stats", ts.login("admin"), nil, http.StatusOK)
+	var stats map[string]interface{}
+	resp.decode(t, &stats)
+	if stats["total_quantity"] != float64(1) || stats["order_count"] != float64(1) ||
+		stats["total_amount"] != "14999.00" || stats["average_amount"] != "14999.00" {
+		t.Errorf("销售统计 = %v", stats)
+	}
+	if product, ok := stats["product"].(map[string]interface{}); !ok || product["id"] != float64(fixtureMacBookID) {
+		t.Errorf("销售统计缺少商品信息: %v", stats["product"])
+	}
+
+	ts.expect(http.MethodGet, "/products/3/stats", ts.login("lisi"), nil, http.StatusForbidden)
+}
+
+func TestProductSalesStatsByStatus(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan, admin := ts.login("zhangsan"), ts.login("admin")
+
+	// placeOrder 张三购买 quantity 个 iPhone，依次执行 events
+	placeOrder := func(quantity int, events ...OrderEvent) {
+		req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureIPhoneID, Quantity: quantity}}}
+		var order Order
+		ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated).decode(t, &order)
+		for _, event := range events {
+			ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/%s", order.ID, event), zhangsan, nil, http.StatusOK)
+		}
+	}
+	placeOrder(2, OrderEventPay, OrderEventCancel) // 支付后取消（退款）
+	placeOrder(1, OrderEventCancel)                // 未支付取消
+	placeOrder(3)                                  // 待支付
+
+	stats := func(query string, status int) ProductSalesStats {
+		t.Helper()
+		var stats ProductSalesStats
+		resp := ts.expect(http.MethodGet, fmt.Sprintf("/products/%d/stats%s", fixtureIPhoneID, query), admin, nil, status)
+		if status == http.StatusOK {
+			resp.decode(t, &stats)
+		}
+		return stats
+	}
+
+	// 默认只统计已支付、已发货、已完成的订单：测试数据中的已支付订单 iPhone × 1
+	got := stats("", http.StatusOK)
+	want := SalesFigures{
+		TotalQuantity: 1, TotalAmount: Yuan(7999), OrderCount: 1, AverageAmount: Yuan(7999),
+		RefundedQuantity: 2, RefundedAmount: Yuan(15998), CancelledQuantity: 1,
+	}
+	if got.SalesFigures != want || len(got.Buckets) != 0 {
+		t.Errorf("默认统计 = %+v，期望 %+v", got.SalesFigures, want)
+	}
+
+	// 指定订单状态
+	got = stats("?status=0,1", http.StatusOK)
+	if got.TotalQuantity != 4 || got.OrderCount != 2 || got.TotalAmount != Yuan(31996) || got.AverageAmount != Yuan(15998) {
+		t.Errorf("status=0,1 的统计 = %+v", got.SalesFigures)
+	}
+
+	// 下单时间范围
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	if got = stats("?start_date="+tomorrow, http.StatusOK); got.SalesFigures != (SalesFigures{}) {
+		t.Errorf("明天之后的统计 = %+v", got.SalesFigures)
+	}
+
+	// 分桶：所有订单都在今天下单，各粒度都只有一个分桶，数据与总计一致
+	for _, interval := range []string{SalesIntervalDay, SalesIntervalWeek, SalesIntervalMonth} {
+		got = stats("?interval="+interval, http.StatusOK)
+		if got.Interval != interval || len(got.Buckets) != 1 || got.Buckets[0].SalesFigures != want {
+			t.Errorf("interval=%s 的分桶 = %+v", interval, got.Buckets)
+			continue
+		}
+		period, err := time.Parse("2006-01-02", got.Buckets[0].Period)
+		switch {
+		case err != nil:
+			t.Errorf("interval=%s 的分桶日期 %q 格式错误", interval, got.Buckets[0].Period)
+		case interval == SalesIntervalWeek && period.Weekday() != time.Monday:
+			t.Errorf("周分桶应从周一开始: %s", got.Buckets[0].Period)
+		case interval == SalesIntervalMonth && period.Day() != 1:
+			t.Errorf("月分桶应从 1 日开始: %s", got.Buckets[0].Period)
+		}
+	}
+
+	stats("?interval=year", http.StatusBadRequest)
+	stats("?status=9", http.StatusBadRequest)
+	// 已取消的订单已经单独统计，不能再计入销量
+	stats("?status=4", http.StatusBadRequest)
+	stats("?status=1,4", http.StatusBadRequest)
+	ts.expect(http.MethodGet, "/products/999/stats", admin, nil, http.StatusNotFound)
+}
+
+func TestGetOrders(t *testing.T) {
+	ts := newTestServer(t)
+
+	// 顾客只能看到自己的订单
+	zhangsan := ts.login("zhangsan")
+	resp := ts.expect(http.MethodGet, "/orders", zhangsan, nil, http.StatusOK)
+	var page pageResult[Order]
+	resp.decode(t, &page)
+	if page.Total != 2 {
+		t.Fatalf("张三的订单数 = %d，期望 2", page.Total)
+	}
+	for _, order := range page.Items {
+		if order.UserID != fixtureZhangsanID || len(order.OrderItems) == 0 {
+			t.Errorf("订单 = %+v", order)
+		}
+	}
+	ts.expect(http.MethodGet, "/orders?user_id=1", zhangsan, nil, http.StatusOK)
+	ts.expect(http.MethodGet, "/orders?user_id=2", zhangsan, nil, http.StatusForbidden)
+
+	// 运营可以查看所有订单并按条件过滤
+	operator := ts.login("operator")
+	resp = ts.expect(http.MethodGet, "/orders", operator, nil, http.StatusOK)
+	resp.decode(t, &page)
+	if page.Total != 3 {
+		t.Errorf("所有订单数 = %d，期望 3", page.Total)
+	}
+	resp = ts.expect(http.MethodGet, fmt.Sprintf("/orders?status=%d,%d", OrderStatusPaid, OrderStatusShipped), operator, nil, http.StatusOK)
+	resp.decode(t, &page)
+	if !sameIDs(orderIDs(page.Items), []uint{fixtureShippedOrderID, fixturePaidOrderID}) {
+		t.Errorf("按状态过滤 = %v", orderIDs(page.Items))
+	}
+	resp = ts.expect(http.MethodGet, "/orders?user_id=2&sort=pay_amount", operator, nil, http.StatusOK)
+	resp.decode(t, &page)
+	if !sameIDs(orderIDs(page.Items), []uint{fixtureShippedOrderID}) {
+		t.Errorf("按用户过滤 = %v", orderIDs(page.Items))
+	}
+
+	ts.expect(http.MethodGet, "/orders?user_id=abc", operator, nil, http.StatusBadRequest)
+	ts.expect(http.MethodGet, "/orders?status=9x", operator, nil, http.StatusBadRequest)
+}
+
+func TestGetOrder(t *testing.T) {
+	ts := newTestServer(t)
+
+	for _, path := range []string{"/orders/1", "/orders/1/products"} {
+		resp := ts.expect(http.MethodGet, path, ts.login("zhangsan"), nil, http.StatusOK)
+		var order Order
+		resp.decode(t, &order)
+		if order.ID != fixturePaidOrderID || order.Status != OrderStatusPaid || order.PayAmount.String() != "7999.00" {
+			t.Errorf("%s: 订单 = %+v", path, order)
+		}
+		if order.ReceiverName != "张三" || order.AddressDetail == "" {
+			t.Errorf("%s: 收货地址快照 = %q %q", path, order.ReceiverName, order.AddressDetail)
+		}
+		if len(order.OrderItems) != 1 || order.OrderItems[0].ProductID != fixtureIPhoneID {
+			t.Errorf("%s: 订单明细 = %+v", path, order.OrderItems)
+		}
+
+		// 其他顾客不能查看，运营可以
+		ts.expect(http.MethodGet, path, ts.login("lisi"), nil, http.StatusForbidden)
+		ts.expect(http.MethodGet, path, ts.login("operator"), nil, http.StatusOK)
+	}
+}
+
+func TestCreateOrder(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	req := CreateOrderRequest{
+		AddressID: 2,
+		Items: []CreateOrderItemRequest{
+			{ProductID: fixtureAirPodsID, Quantity: 2},
+			{ProductID: fixtureCaseID, Quantity: 3},
+		},
+		Remark: "测试订单",
+	}
+	resp := ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated)
+	var order Order
+	resp.decode(t, &order)
+	if order.UserID != fixtureZhangsanID || order.Status != OrderStatusPending {
+		t.Errorf("未传 user_id 时应为当前用户下单: %+v", order)
+	}
+	if order.TotalAmount.String() != "4095.00" || order.PayAmount.String() != "4095.00" {
+		t.Errorf("订单金额 = %s / %s，期望 4095.00", order.TotalAmount, order.PayAmount)
+	}
+	if order.District != "朝阳区" || len(order.OrderItems) != 2 {
+		t.Errorf("订单 = %+v", order)
+	}
+
+	// 下单只预占库存，支付后才扣减库存、增加销量
+	var product Product
+	ts.db.First(&product, fixtureAirPodsID)
+	if product.Stock != 200 || product.ReservedStock != 2 || product.Sales != 0 {
+		t.Errorf("下单后库存 = %d，预占 = %d，销量 = %d", product.Stock, product.ReservedStock, product.Sales)
+	}
+
+	failures := []struct {
+		token  string
+		req    CreateOrderRequest
+		status int
+	}{
+		// 库存不足
+		{zhangsan, CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureMacBookID, Quantity: 51}}}, http.StatusConflict},
+		// 使用其他用户的地址
+		{zhangsan, CreateOrderRequest{AddressID: 3, Items: []CreateOrderItemRequest{{ProductID: fixtureCaseID, Quantity: 1}}}, http.StatusBadRequest},
+		// 商品不存在
+		{zhangsan, CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: 999, Quantity: 1}}}, http.StatusNotFound},
+		// 地址不存在
+		{zhangsan, CreateOrderRequest{AddressID: 999, Items: []CreateOrderItemRequest{{ProductID: fixtureCaseID, Quantity: 1}}}, http.StatusNotFound},
+		// 参数错误
+		{zhangsan, CreateOrderRequest{AddressID: 1}, http.StatusBadRequest},
+		{zhangsan, CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureCaseID, Quantity: 0}}}, http.StatusBadRequest},
+		// 顾客不能为其他用户下单
+		{zhangsan, CreateOrderRequest{UserID: fixtureLisiID, AddressID: 3, Items: []CreateOrderItemRequest{{ProductID: fixtureCaseID, Quantity: 1}}}, http.StatusForbidden},
+	}
+	for i, tc := range failures {
+		if resp := ts.do(http.MethodPost, "/orders", tc.token, tc.req); resp.Status != tc.status || resp.Code != tc.status {
+			t.Errorf("第 %d 个用例: 状态码 = %d / %d，期望 %d（%s）", i, resp.Status, resp.Code, tc.status, resp.Message)
+		}
+	}
+
+	// 下架的商品不能购买
+	ts.db.Model(&Product{}).Where("id = ?", fixtureCaseID).Update("status", ProductStatusOffSale)
+	ts.expect(http.MethodPost, "/orders", zhangsan, CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureCaseID, Quantity: 1}}}, http.StatusBadRequest)
+
+	// 失败的下单不能扣减库存
+	var macBook Product
+	ts.db.First(&macBook, fixtureMacBookID)
+	if macBook.Stock != 50 || macBook.ReservedStock != 0 {
+		t.Errorf("下单失败后库存 = %d，预占 = %d，期望 50、0", macBook.Stock, macBook.ReservedStock)
+	}
+
+	// 管理员可以为其他用户下单
+	ts.expect(http.MethodPost, "/orders", ts.login("admin"), CreateOrderRequest{UserID: fixtureLisiID, AddressID: 3, Items: []CreateOrderItemRequest{{ProductID: fixtureIPhoneID, Quantity: 1}}}, http.StatusCreated)
+}
+
+func TestOrderTransitions(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+	operator := ts.login("operator")
+
+	transition := func(token, action string, orderID uint, body interface{}, status int) Order {
+		t.Helper()
+		resp := ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/%s", orderID, action), token, body, status)
+		var order Order
+		if status == http.StatusOK {
+			resp.decode(t, &order)
+		}
+		return order
+	}
+
+	// 待支付 -> 已支付 -> 已发货 -> 已完成
+	order := transition(zhangsan, "pay", fixturePendingOrderID, PayOrderRequest{PayMethod: "微信支付"}, http.StatusOK)
+	if order.Status != OrderStatusPaid || order.PayMethod != "微信支付" || order.PayTime == nil {
+		t.Errorf("支付后 = %+v", order)
+	}
+	transition(zhangsan, "pay", fixturePendingOrderID, nil, http.StatusConflict)
+	// 顾客不能发货
+	transition(zhangsan, "ship", fixturePendingOrderID, nil, http.StatusForbidden)
+	order = transition(operator, "ship", fixturePendingOrderID, nil, http.StatusOK)
+	if order.Status != OrderStatusShipped || order.ShipTime == nil {
+		t.Errorf("发货后 = %+v", order)
+	}
+	transition(zhangsan, "cancel", fixturePendingOrderID, nil, http.StatusConflict)
+	order = transition(zhangsan, "complete", fixturePendingOrderID, nil, http.StatusOK)
+	if order.Status != OrderStatusCompleted || order.CompleteTime == nil {
+		t.Errorf("确认收货后 = %+v", order)
+	}
+
+	// 顾客不能操作其他用户的订单
+	transition(zhangsan, "complete", fixtureShippedOrderID, nil, http.StatusForbidden)
+
+	// 取消已支付的订单会恢复库存和销量
+	var before Product
+	ts.db.First(&before, fixtureIPhoneID)
+	order = transition(zhangsan, "cancel", fixturePaidOrderID, nil, http.StatusOK)
+	if order.Status != OrderStatusCancelled {
+		t.Errorf("取消后 = %+v", order)
+	}
+	var after Product
+	ts.db.First(&after, fixtureIPhoneID)
+	if after.Stock != before.Stock+1 || after.Sales != before.Sales-1 {
+		t.Errorf("取消后库存 %d -> %d，销量 %d -> %d", before.Stock, after.Stock, before.Sales, after.Sales)
+	}
+}
+
+func TestStockReservation(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	place := func(quantity int, status int) Order {
+		t.Helper()
+		req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureAirPodsID, Quantity: quantity}}}
+		var order Order
+		if resp := ts.expect(http.MethodPost, "/orders", zhangsan, req, status); status == http.StatusCreated {
+			resp.decode(t, &order)
+		}
+		return order
+	}
+	transition := func(order Order, action string) {
+		t.Helper()
+		ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/%s", order.ID, action), zhangsan, nil, http.StatusOK)
+	}
+	check := func(step string, stock, reserved, sales int) {
+		t.Helper()
+		var product Product
+		ts.db.First(&product, fixtureAirPodsID)
+		if product.Stock != stock || product.ReservedStock != reserved || product.Sales != sales {
+			t.Errorf("%s: 库存 = %d，预占 = %d，销量 = %d，期望 %d、%d、%d",
+				step, product.Stock, product.ReservedStock, product.Sales, stock, reserved, sales)
+		}
+	}
+	reservationStatus := func(order Order) int8 {
+		t.Helper()
+		var reservation StockReservation
+		if err := ts.db.Where("order_id = ?", order.ID).First(&reservation).Error; err != nil {
+			t.Fatalf("订单 %d 的预占记录: %v", order.ID, err)
+		}
+		return reservation.Status
+	}
+
+	// 预占后可售库存为 0，不能再下单
+	paid := place(2, http.StatusCreated)
+	unpaid := place(198, http.StatusCreated)
+	check("下单后", 200, 200, 0)
+	place(1, http.StatusConflict)
+
+	transition(paid, "pay")
+	check("支付后", 198, 198, 2)
+	if reservationStatus(paid) != ReservationStatusDeducted {
+		t.Error("支付后预占记录应为已扣减")
+	}
+
+	transition(unpaid, "cancel")
+	check("取消未支付订单后", 198, 0, 2)
+	if reservationStatus(unpaid) != ReservationStatusReleased {
+		t.Error("取消后预占记录应为已释放")
+	}
+	place(1, http.StatusCreated)
+
+	transition(paid, "cancel")
+	check("取消已支付订单后", 200, 1, 0)
+	if reservationStatus(paid) != ReservationStatusReleased {
+		t.Error("支付后取消，预占记录应为已释放")
+	}
+}
+
+func productIDs(products []Product) []uint {
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func orderIDs(orders []Order) []uint {
+	ids := make([]uint, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+// sameIDs 按顺序比较两个 ID 列表
+func sameIDs(got, want []uint) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 库存预占
+//
+// 商品的 stock 是实际库存，reserved_stock 是待支付订单占用的数量，可售库存 = stock - reserved_stock：
+//   - 下单：reserved_stock 增加，stock 不变，为每个商品写入一条已预占的 StockReservation
+//   - 支付：预占转为扣减，stock 和 reserved_stock 同时减少，销量增加
+//   - 取消或超时未支付：已预占的释放 reserved_stock；已扣减的（支付后取消）归还 stock 并扣回销量
+//
+// 引入预占之前的订单在下单时已经扣减了库存、增加了销量，没有预占记录，取消时按原来的方式归还
+//
+// stock、reserved_stock、sales 的每一次变动都通过 changeStock 写入库存流水（StockMovement），
+// 不要绕过它直接更新这三个字段，否则流水无法和库存对账
+
+// 库存预占状态
+const (
+	ReservationStatusReserved int8 = 0 // 已预占
+	ReservationStatusDeducted int8 = 1 // 已扣减
+	ReservationStatusReleased int8 = 2 // 已释放
+)
+
+// 库存流水类型
+const (
+	StockMovementOrderPlaced    = "order_placed"    // 下单预占
+	StockMovementOrderPaid      = "order_paid"      // 支付扣减
+	StockMovementOrderCancelled = "order_cancelled" // 取消订单释放预占或归还库存
+	StockMovementAdjustment     = "adjustment"      // 人工调整（盘点差异、报损等），数量可以为负
+	StockMovementRestock        = "restock"         // 进货入库
+	StockMovementReturn         = "return"          // 退货入库
+)
+
+// 库存调整相关的业务错误
+var (
+	ErrInvalidStockAdjustment = errors.New("无效的库存调整")
+	ErrStockBelowReserved     = errors.New("调整后的库存不能少于已预占的数量")
+)
+
+// StockAdjustmentRequest 人工调整库存请求参数
+type StockAdjustmentRequest struct {
+	Type     string `json:"type" binding:"required,oneof=adjustment restock return"`
+	Quantity int    `json:"quantity" binding:"required"` // 库存变动数量，restock 和 return 必须为正数，adjustment 为负数时减少库存
+	OrderID  *uint  `json:"order_id"`                    // 退货对应的订单，type 为 return 时必须传，订单已支付且包含该商品
+	Reason   string `json:"reason" binding:"required,max=255"`
+}
+
+// stockDelta 一次变动对 stock、reserved_stock、sales 的增量
+type stockDelta struct {
+	Stock    int
+	Reserved int
+	Sales    int
+}
+
+// changeStock 按 delta 更新商品的库存、预占数量和销量，并写入一条库存流水
+// condition 为附加的更新条件（例如可售库存充足），条件不满足或商品不存在时不更新，返回 false
+// 更新之后再读取商品，变动前的数量由变动后的数量减去增量得到，不需要提前加锁读取
+func changeStock(tx *gorm.DB, movement *StockMovement, delta stockDelta, condition ...interface{}) (bool, error) {
+	updates := make(map[string]interface{}, 3)
+	if delta.Stock != 0 {
+		updates["stock"] = gorm.Expr("stock + ?", delta.Stock)
+	}
+	if delta.Reserved != 0 {
+		updates["reserved_stock"] = gorm.Expr("reserved_stock + ?", delta.Reserved)
+	}
+	if delta.Sales != 0 {
+		updates["sales"] = gorm.Expr("sales + ?", delta.Sales)
+	}
+
+	query := tx.Model(&Product{}).Where("id = ?", movement.ProductID)
+	if len(condition) > 0 {
+		query = query.Where(condition[0], condition[1:]...)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("更新库存失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	var product Product
+	if err := tx.Select("id", "stock", "reserved_stock", "sales").First(&product, movement.ProductID).Error; err != nil {
+		return false, fmt.Errorf("查询商品库存失败: %v", err)
+	}
+	movement.StockAfter, movement.StockBefore = product.Stock, product.Stock-delta.Stock
+	movement.ReservedAfter, movement.ReservedBefore = product.ReservedStock, product.ReservedStock-delta.Reserved
+	movement.SalesAfter, movement.SalesBefore = product.Sales, product.Sales-delta.Sales
+	if err := tx.Create(movement).Error; err != nil {
+		return false, fmt.Errorf("写入库存流水失败: %v", err)
+	}
+	return true, nil
+}
+
+// orderMovement 订单引起的库存流水，operatorID 为 0 表示系统自动执行
+func orderMovement(order *Order, productID uint, movementType string, quantity int, operatorID uint, reason string) *StockMovement {
+	movement := &StockMovement{
+		ProductID: productID,
+		OrderID:   &order.ID,
+		Type:      movementType,
+		Quantity:  quantity,
+		Reason:    reason,
+	}
+	if operatorID != 0 {
+		movement.OperatorID = &operatorID
+	}
+	return movement
+}
+
+// reserveOrderStock 为订单的每个商品预占库存并写入预占记录，需要在订单创建之后调用
+// 通过 stock - reserved_stock >= ? 条件保证并发下不会超卖，可售库存不足时返回 ErrInsufficientStock
+func reserveOrderStock(tx *gorm.DB, order *Order, operatorID uint) error {
+	reservations := make([]StockReservation, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		movement := orderMovement(order, item.ProductID, StockMovementOrderPlaced, item.Quantity, operatorID,
+			"下单 "+order.OrderNo)
+		ok, err := changeStock(tx, movement, stockDelta{Reserved: item.Quantity},
+			"stock - reserved_stock >= ?", item.Quantity)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrInsufficientStock, item.ProductName)
+		}
+		reservations = append(reservations, StockReservation{
+			OrderID:   order.ID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Status:    ReservationStatusReserved,
+		})
+	}
+	if err := tx.Create(&reservations).Error; err != nil {
+		return fmt.Errorf("创建库存预占记录失败: %v", err)
+	}
+	return nil
+}
+
+// deductReservedStock 订单支付时将预占转为扣减：减少库存和预占数量，增加销量
+// 预占时已保证 stock >= reserved_stock，这里不会把库存扣成负数
+func deductReservedStock(tx *gorm.DB, order *Order, operatorID uint) error {
+	var reservations []StockReservation
+	if err := tx.Where("order_id = ? AND status = ?", order.ID, ReservationStatusReserved).
+		Find(&reservations).Error; err != nil {
+		return fmt.Errorf("查询库存预占记录失败: %v", err)
+	}
+	for _, reservation := range reservations {
+		movement := orderMovement(order, reservation.ProductID, StockMovementOrderPaid, reservation.Quantity, operatorID,
+			"支付 "+order.OrderNo)
+		delta := stockDelta{Stock: -reservation.Quantity, Reserved: -reservation.Quantity, Sales: reservation.Quantity}
+		if _, err := changeStock(tx, movement, delta); err != nil {
+			return err
+		}
+	}
+	return updateReservationStatus(tx, order.ID, []int8{ReservationStatusReserved}, ReservationStatusDeducted)
+}
+
+// releaseOrderStock 订单取消时释放占用的库存，operatorID 为 0 表示超时未支付被自动取消
+// 已预占的减少预占数量；已扣减的归还库存并扣回销量；没有预占记录的早期订单归还库存并扣回销量
+func releaseOrderStock(tx *gorm.DB, order *Order, operatorID uint) error {
+	reason := "取消 " + order.OrderNo
+	if operatorID == 0 {
+		reason = order.OrderNo + " 超时未支付，自动取消"
+	}
+	release := func(productID uint, quantity int, delta stockDelta) error {
+		movement := orderMovement(order, productID, StockMovementOrderCancelled, quantity, operatorID, reason)
+		_, err := changeStock(tx, movement, delta)
+		return err
+	}
+
+	var reservations []StockReservation
+	if err := tx.Where("order_id = ?", order.ID).Find(&reservations).Error; err != nil {
+		return fmt.Errorf("查询库存预占记录失败: %v", err)
+	}
+	if len(reservations) == 0 {
+		for _, item := range order.OrderItems {
+			if err := release(item.ProductID, item.Quantity, stockDelta{Stock: item.Quantity, Sales: -item.Quantity}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, reservation := range reservations {
+		quantity := reservation.Quantity
+		var err error
+		switch reservation.Status {
+		case ReservationStatusReserved:
+			err = release(reservation.ProductID, quantity, stockDelta{Reserved: -quantity})
+		case ReservationStatusDeducted:
+			err = release(reservation.ProductID, quantity, stockDelta{Stock: quantity, Sales: -quantity})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return updateReservationStatus(tx, order.ID,
+		[]int8{ReservationStatusReserved, ReservationStatusDeducted}, ReservationStatusReleased)
+}
+
+// updateReservationStatus 将订单中处于 from 状态的预占记录更新为 to 状态
+func updateReservationStatus(tx *gorm.DB, orderID uint, from []int8, to int8) error {
+	if err := tx.Model(&StockReservation{}).
+		Where("order_id = ? AND status IN ?", orderID, from).
+		Update("status", to).Error; err != nil {
+		return fmt.Errorf("更新库存预占状态失败: %v", err)
+	}
+	return nil
+}
+
+// returnableOrderStatuses 可以退货的订单状态：库存已经扣减、没有被取消归还
+// 待支付的订单库存只是预占；已取消的订单取消时已经归还了库存，再退货会重复入库
+var returnableOrderStatuses = []int8{OrderStatusPaid, OrderStatusShipped, OrderStatusCompleted}
+
+// adjustStock 人工调整商品库存（盘点调整、进货、退货），在一个事务中更新库存并写入流水
+// 调整后的库存不能少于已预占的数量，否则待支付的订单支付时会把库存扣成负数
+// 退货的数量不能超过订单中该商品的购买数量减去之前已退货的数量
+func adjustStock(db *gorm.DB, productID uint, req StockAdjustmentRequest, operatorID uint) (*StockMovement, error) {
+	if req.Type != StockMovementAdjustment && req.Quantity <= 0 {
+		return nil, fmt.Errorf("%w: %s 的数量必须大于 0", ErrInvalidStockAdjustment, req.Type)
+	}
+	if req.Type == StockMovementReturn && req.OrderID == nil {
+		return nil, fmt.Errorf("%w: 退货必须指定订单", ErrInvalidStockAdjustment)
+	}
+
+	movement := &StockMovement{
+		ProductID:  productID,
+		OrderID:    req.OrderID,
+		OperatorID: &operatorID,
+		Type:       req.Type,
+		Quantity:   req.Quantity,
+		Reason:     req.Reason,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var product Product
+		if err := tx.First(&product, productID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return fmt.Errorf("查询商品失败: %v", err)
+		}
+
+		if req.OrderID != nil {
+			// 锁定订单，同一订单的并发退货串行执行，合计数量不会超过购买数量
+			var order Order
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, *req.OrderID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrOrderNotFound
+				}
+				return fmt.Errorf("查询订单失败: %v", err)
+			}
+			// 同一订单中同一商品可能有多条明细，购买数量取合计
+			var ordered struct {
+				Items    int64
+				Quantity int64
+			}
+			if err := tx.Model(&OrderItem{}).
+				Where("order_id = ? AND product_id = ?", order.ID, productID).
+				Select("COUNT(*) AS items, COALESCE(SUM(quantity), 0) AS quantity").
+				Scan(&ordered).Error; err != nil {
+				return fmt.Errorf("查询订单明细失败: %v", err)
+			}
+			if ordered.Items == 0 {
+				return fmt.Errorf("%w: 订单 %s 中没有该商品", ErrInvalidStockAdjustment, order.OrderNo)
+			}
+			if req.Type == StockMovementReturn {
+				if !slices.Contains(returnableOrderStatuses, order.Status) {
+					return fmt.Errorf("%w: 订单 %s 未支付或已取消，不能退货", ErrInvalidStockAdjustment, order.OrderNo)
+				}
+				var returned int64
+				if err := tx.Model(&StockMovement{}).
+					Where("order_id = ? AND product_id = ? AND type = ?", order.ID, productID, StockMovementReturn).
+					Select("COALESCE(SUM(quantity), 0)").Scan(&returned).Error; err != nil {
+					return fmt.Errorf("查询退货记录失败: %v", err)
+				}
+				if remaining := ordered.Quantity - returned; int64(req.Quantity) > remaining {
+					return fmt.Errorf("%w: 订单 %s 中该商品购买 %d 件，已退货 %d 件，最多还能退货 %d 件",
+						ErrInvalidStockAdjustment, order.OrderNo, ordered.Quantity, returned, remaining)
+				}
+			}
+		}
+
+		ok, err := changeStock(tx, movement, stockDelta{Stock: req.Quantity},
+			"stock + ? >= reserved_stock", req.Quantity)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: 当前库存 %d，已预占 %d", ErrStockBelowReserved, product.Stock, product.ReservedStock)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 日志格式
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// requestIDHeader 请求 ID 的请求头和响应头
+// 客户端或网关传入的请求 ID 会被沿用，否则生成新的请求 ID
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID 允许沿用的请求 ID 格式，避免把任意内容写入日志
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// requestIDKey 请求 ID 在 context 中的键
+type requestIDKey struct{}
+
+// withRequestID 将请求 ID 保存到 context
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// requestIDFromContext 读取 context 中的请求 ID，没有时返回空字符串
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// newRequestID 生成 32 位十六进制的请求 ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newLogger 按日志配置创建结构化日志
+func newLogger(config LogConfig, w io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: slogLevel(config.Level)}
+	var handler slog.Handler
+	if config.Format == LogFormatJSON {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// slogLevel 将配置中的日志级别转换为 slog 级别
+func slogLevel(level string) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// contextHandler 为每条日志附加 context 中的请求 ID 和链路追踪 ID
+// 使用 InfoContext 等带 context 的方法记录日志时，同一请求的请求日志和 SQL 日志可以通过 request_id 关联，
+// 也可以通过 trace_id 在链路追踪系统中找到对应的请求
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := requestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// RequestID 请求 ID 中间件：沿用或生成请求 ID，写入响应头并保存到请求的 context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+		c.Request = c.Request.WithContext(withRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// RequestLogger 请求日志中间件，每个请求结束后记录一条日志
+// 5xx 记录为 error，4xx 记录为 warn，其他记录为 info
+func RequestLogger(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		log.LogAttrs(c.Request.Context(), level, "请求", attrs...)
+	}
+}
+
+// Recovery 捕获 handler 中的 panic，记录错误日志和调用栈后返回 500
+func Recovery(log *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		log.ErrorContext(c.Request.Context(), "处理请求时发生 panic", "error", err, "stack", string(debug.Stack()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response{
+			Code:    http.StatusInternalServerError,
+			Message: "服务器内部错误",
+		})
+	})
+}
+
+// gormLogger 将 GORM 的日志输出到 slog，实现 gorm logger.Interface
+// 默认只记录执行失败和超过慢查询阈值的 SQL；log.sql 开启或调用 db.Debug() 时记录所有 SQL
+type gormLogger struct {
+	log           *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger 按日志配置创建 GORM 日志
+func newGormLogger(log *slog.Logger, config LogConfig) *gormLogger {
+	level := logger.Warn
+	switch {
+	case config.SQL:
+		level = logger.Info
+	case config.Level == LogLevelError:
+		level = logger.Error
+	}
+	return &gormLogger{log: log, level: level, slowThreshold: config.SlowQueryThreshold}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		l.log.InfoContext(ctx, msg, "data", data)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		l.log.WarnContext(ctx, msg, "data", data)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		l.log.ErrorContext(ctx, msg, "data", data)
+	}
+}
+
+// Trace 每条 SQL 执行后调用
+// 查询不到数据（gorm.ErrRecordNotFound）是正常的业务结果，不记录为错误
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []slog.Attr {
+		sql, rows := fc()
+		return []slog.Attr{
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("elapsed_ms", float64(elapsed.Microseconds())/1000),
+		}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		l.log.LogAttrs(ctx, slog.LevelError, "SQL 执行失败", append(attrs(), slog.String("error", err.Error()))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		l.log.LogAttrs(ctx, slog.LevelWarn, "慢查询", append(attrs(), slog.Float64("threshold_ms", float64(l.slowThreshold.Microseconds())/1000))...)
+	case l.level >= logger.Info:
+		l.log.LogAttrs(ctx, slog.LevelInfo, "SQL", attrs()...)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// captureLogs 创建输出 JSON 日志到缓冲区的 logger
+func captureLogs(level string) (*bytes.Buffer, LogConfig) {
+	return &bytes.Buffer{}, LogConfig{Level: level, Format: LogFormatJSON}
+}
+
+// logLines 解析缓冲区中的 JSON 日志
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("日志不是合法的 JSON: %v\n%s", err, line)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// captureDefaultLogger 测试期间将 slog 的默认 logger 替换为输出到缓冲区的 JSON logger，结束后恢复
+func captureDefaultLogger(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf, config := captureLogs(LogLevelInfo)
+	previous := slog.Default()
+	slog.SetDefault(newLogger(config, buf))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return buf
+}
+
+// newLoggingTestDB 创建使用指定日志配置的内存数据库
+func newLoggingTestDB(t *testing.T, buf *bytes.Buffer, config LogConfig) *gorm.DB {
+	t.Helper()
+	db, err := connectDB(DBConfig{Driver: DriverSQLite, Database: sqliteMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeDB(db) })
+	if _, err := migrateUp(db, 0); err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = newGormLogger(newLogger(config, buf), config)
+	return db
+}
+
+func TestRequestIDInRequestAndSQLLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf, config := captureLogs(LogLevelInfo)
+	config.SQL = true
+	log := newLogger(config, buf)
+	db := newLoggingTestDB(t, buf, config)
+
+	r := gin.New()
+	r.Use(RequestID(), RequestLogger(log), Recovery(log))
+	r.GET("/products", func(c *gin.Context) {
+		var count int64
+		db.WithContext(c.Request.Context()).Model(&Product{}).Count(&count)
+		c.JSON(http.StatusOK, Response{Code: http.StatusOK, Message: "查询成功"})
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	cases := []struct {
+		path, incoming string
+		status         int
+		reuse          bool
+	}{
+		{"/products", "", http.StatusOK, false},
+		{"/products", "gateway-abc.123", http.StatusOK, true},
+		{"/products", "bad id with spaces", http.StatusOK, false},
+		{"/panic", "", http.StatusInternalServerError, false},
+	}
+	for _, tc := range cases {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.incoming != "" {
+			req.Header.Set(requestIDHeader, tc.incoming)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		requestID := w.Header().Get(requestIDHeader)
+		if w.Code != tc.status || !validRequestID.MatchString(requestID) || (requestID == tc.incoming) != tc.reuse {
+			t.Errorf("%s %q: 状态码 = %d，请求 ID = %q", tc.path, tc.incoming, w.Code, requestID)
+		}
+
+		// 同一请求的每一条日志（SQL、panic、请求日志）都带有相同的请求 ID
+		lines := logLines(t, buf)
+		if len(lines) < 2 {
+			t.Fatalf("%s: 日志条数 = %d\n%s", tc.path, len(lines), buf.String())
+		}
+		for _, line := range lines {
+			if line["request_id"] != requestID {
+				t.Errorf("%s: 日志缺少请求 ID %s: %v", tc.path, requestID, line)
+			}
+		}
+		last := lines[len(lines)-1]
+		if last["msg"] != "请求" || last["status"] != float64(tc.status) {
+			t.Errorf("%s: 请求日志 = %v", tc.path, last)
+		}
+	}
+}
+
+func TestGormLoggerLevels(t *testing.T) {
+	buf, config := captureLogs(LogLevelInfo)
+	db := newLoggingTestDB(t, buf, config)
+
+	// 默认不记录 SQL，查询不到数据也不记录为错误
+	buf.Reset()
+	var product Product
+	db.Model(&Product{}).Count(new(int64))
+	db.First(&product, 1000)
+	if buf.Len() != 0 {
+		t.Errorf("默认配置不应记录 SQL:\n%s", buf.String())
+	}
+
+	// 执行失败
+	db.Exec("SELECT * FROM no_such_table")
+	lines := logLines(t, buf)
+	if len(lines) != 1 || lines[0]["msg"] != "SQL 执行失败" || lines[0]["level"] != "ERROR" {
+		t.Errorf("执行失败的日志 = %v", lines)
+	}
+
+	// 慢查询
+	buf.Reset()
+	config.SlowQueryThreshold = time.Nanosecond
+	db.Logger = newGormLogger(newLogger(config, buf), config)
+	db.Model(&Product{}).Count(new(int64))
+	lines = logLines(t, buf)
+	if len(lines) != 1 || lines[0]["msg"] != "慢查询" || !strings.Contains(lines[0]["sql"].(string), "products") {
+		t.Errorf("慢查询日志 = %v", lines)
+	}
+
+	// error 级别不记录慢查询
+	buf.Reset()
+	config.Level = LogLevelError
+	db.Logger = newGormLogger(newLogger(config, buf), config)
+	db.Model(&Product{}).Count(new(int64))
+	if buf.Len() != 0 {
+		t.Errorf("error 级别不应记录慢查询:\n%s", buf.String())
+	}
+}
+
+func TestTraceIDInLogs(t *testing.T) {
+	buf, config := captureLogs(LogLevelInfo)
+	log := newLogger(config, buf)
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa},
+	})
+	log.InfoContext(trace.ContextWithSpanContext(context.Background(), spanContext), "SQL")
+	log.InfoContext(context.Background(), "SQL")
+
+	lines := logLines(t, buf)
+	if len(lines) != 2 || lines[0]["trace_id"] != spanContext.TraceID().String() || lines[0]["span_id"] != spanContext.SpanID().String() {
+		t.Errorf("日志缺少 trace_id 或 span_id: %v", lines)
+	}
+	if _, ok := lines[1]["trace_id"]; ok {
+		t.Errorf("没有链路追踪时不应记录 trace_id: %v", lines[1])
+	}
+}
@@ -1,64 +1,187 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// 从环境变量读取配置，如果没有则使用默认值
-	config := DBConfig{
-		Host:     getEnv("DB_HOST", "127.0.0.1"),
-		Port:     getEnv("DB_PORT", "3306"),
-		User:     getEnv("DB_USER", "root"),
-		Password: getEnv("DB_PASSWORD", "mima123"),
-		Database: getEnv("DB_NAME", "table_design"),
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// runServeCommand 执行 serve 命令：连接数据库、执行迁移并启动 HTTP 服务
+// 用法: serve [-migrate=false]
+func runServeCommand(config *Config, args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	runMigrations := flags.Bool("migrate", true, "启动前执行未执行的迁移；部署脚本已单独执行 migrate up 时可以关闭")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
 	}
 
-	// 连接数据库
-	var err error
-	db, err = connectDB(config)
+	db, err := openDB(config)
 	if err != nil {
-		log.Fatalf("数据库连接失败: %v", err)
+		fmt.Printf("✗ %v\n", err)
+		return exitFailure
 	}
+	defer closeDB(db)
 
-	fmt.Println("✓ 数据库连接成功！")
+	// 设置 Gin 模式（开发模式会显示更多调试信息）
+	gin.SetMode(config.Server.Mode)
 
 	// 执行数据库迁移
-	if err := migrate(db); err != nil {
-		log.Fatalf("数据库迁移失败: %v", err)
+	// 默认执行版本化迁移；server.auto_migrate 开启时改用 AutoMigrate，仅限开发环境（release 模式下配置校验会拒绝）
+	if config.Server.AutoMigrate {
+		if err := autoMigrate(db); err != nil {
+			fmt.Printf("✗ 数据库迁移失败: %v\n", err)
+			return exitFailure
+		}
+	} else if *runMigrations {
+		executed, err := migrateUp(db, 0)
+		for _, migration := range executed {
+			fmt.Printf("✓ 已执行迁移 %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Printf("✗ 数据库迁移失败: %v\n", err)
+			return exitFailure
+		}
 	}
 
-	// 设置 Gin 模式（开发模式会显示更多调试信息）
-	ginMode := getEnv("GIN_MODE", gin.DebugMode)
-	gin.SetMode(ginMode)
+	// 加载认证配置
+	authConfig, err := newAuthConfig(config.Auth)
+	if err != nil {
+		fmt.Printf("✗ 认证配置错误: %v\n", err)
+		return exitFailure
+	}
+
+	// Prometheus 指标：请求、SQL 耗时、连接池状态和订单统计
+	metrics := NewMetrics()
+	if err := metrics.InstrumentDB(db, config.DB.Database); err != nil {
+		fmt.Printf("✗ 注册数据库指标失败: %v\n", err)
+		return exitFailure
+	}
+
+	// 链路追踪：每个请求一个 span，请求中的每条 SQL 一个子 span
+	shutdownTracing, err := setupTracing(context.Background(), config.Tracing, os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 链路追踪配置错误: %v\n", err)
+		return exitFailure
+	}
+	defer func() {
+		// 退出前导出尚未发送的 span，collector 不可用时最多等待 tracingShutdownTimeout
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("导出链路追踪数据失败", "error", err)
+		}
+	}()
+	if err := db.Use(gormTracing{}); err != nil {
+		fmt.Printf("✗ 注册数据库链路追踪失败: %v\n", err)
+		return exitFailure
+	}
+
+	// handler 通过 Server 访问数据，不再依赖全局数据库实例
+	server := NewServer(NewGormRepositories(db), authConfig, func(opts SeedOptions) (*SeedResult, error) {
+		return seedData(db, opts)
+	}, func(ctx context.Context) ReadinessReport {
+		return checkReadiness(ctx, db, config.Server.AutoMigrate)
+	}, metrics)
 
 	// 设置路由
-	r := SetupRoutes()
+	r := SetupRoutes(server, config.Server)
 
-	// 获取端口号，默认 8080
-	port := getEnv("PORT", "8080")
+	port := config.Server.Port
+	srv := &http.Server{
+		Addr:         ":" + port,
+		Handler:      r,
+		ReadTimeout:  config.Server.ReadTimeout,
+		WriteTimeout: config.Server.WriteTimeout,
+		IdleTimeout:  config.Server.IdleTimeout,
+	}
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		fmt.Printf("✗ 服务器启动失败: %v\n", err)
+		return exitFailure
+	}
 
-	fmt.Printf("✓ 服务器启动成功！\n")
+	slog.Info("服务器启动成功", "addr", listener.Addr().String(), "mode", config.Server.Mode)
+	if config.Server.Mode == gin.DebugMode {
+		printEndpoints(port)
+	}
+
+	// 启动服务器，收到 SIGINT 或 SIGTERM 后停止接受新连接，等待处理中的请求完成后退出
+	// 退出时由 defer closeDB 关闭数据库连接池
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 超时未支付订单的自动取消，退出时等待正在执行的一轮结束后再关闭数据库连接池
+	expiryDone := startOrderExpiry(ctx, db, config.Orders, metrics)
+	defer func() {
+		stop()
+		<-expiryDone
+	}()
+	if err := runHTTPServer(ctx, srv, listener, config.Server.ShutdownTimeout); err != nil {
+		slog.Error("服务器停止", "error", err)
+		return exitFailure
+	}
+	slog.Info("服务已停止")
+	return exitOK
+}
+
+// printEndpoints 开发模式下打印常用接口地址
+func printEndpoints(port string) {
 	fmt.Printf("✓ 访问地址: http://localhost:%s\n", port)
 	fmt.Printf("✓ API 文档:\n")
-	fmt.Printf("  - 健康检查: GET http://localhost:%s/health\n", port)
+	fmt.Printf("  - 存活探针: GET http://localhost:%s/livez\n", port)
+	fmt.Printf("  - 就绪探针: GET http://localhost:%s/readyz\n", port)
+	fmt.Printf("  - Prometheus 指标: GET http://localhost:%s/metrics\n", port)
+	fmt.Printf("  - 用户注册: POST http://localhost:%s/auth/register\n", port)
+	fmt.Printf("  - 用户登录: POST http://localhost:%s/auth/login\n", port)
 	fmt.Printf("  - 查询所有用户: GET http://localhost:%s/users\n", port)
 	fmt.Printf("  - 查询用户订单: GET http://localhost:%s/users/:id/orders\n", port)
 	fmt.Printf("  - 查询用户订单及商品: GET http://localhost:%s/users/:id/orders/products\n", port)
 	fmt.Printf("  - 查询所有商品: GET http://localhost:%s/products\n", port)
 	fmt.Printf("  - 查询商品订单: GET http://localhost:%s/products/:id/orders\n", port)
 	fmt.Printf("  - 查询商品统计: GET http://localhost:%s/products/:id/stats\n", port)
+	fmt.Printf("  - 查询库存流水: GET http://localhost:%s/products/:id/stock-history\n", port)
+	fmt.Printf("  - 查询分类树: GET http://localhost:%s/categories\n", port)
+	fmt.Printf("  - 查询分类商品: GET http://localhost:%s/categories/:id/products\n", port)
 	fmt.Printf("  - 查询所有订单: GET http://localhost:%s/orders\n", port)
 	fmt.Printf("  - 查询订单商品: GET http://localhost:%s/orders/:id/products\n", port)
-	fmt.Printf("  - 插入测试数据: POST http://localhost:%s/seed\n", port)
+	fmt.Printf("  - 创建订单: POST http://localhost:%s/orders\n", port)
+	fmt.Printf("  - 订单状态流转: POST http://localhost:%s/orders/:id/{pay,ship,complete,cancel}\n", port)
+	fmt.Printf("  - 生成测试数据: POST http://localhost:%s/seed\n", port)
+}
+
+// runHTTPServer 在 listener 上提供服务，直到 ctx 被取消后优雅关闭
+// 关闭时不再接受新连接，最多等待 shutdownTimeout 让处理中的请求完成
+func runHTTPServer(ctx context.Context, srv *http.Server, listener net.Listener, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("服务器异常退出: %v", err)
+	case <-ctx.Done():
+	}
 
-	// 启动服务器
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("服务器启动失败: %v", err)
-		os.Exit(1)
+	slog.Info("正在关闭服务，等待处理中的请求完成", "timeout", shutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("等待请求完成超时（%s），已强制关闭: %v", shutdownTimeout, err)
 	}
+	return nil
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startHTTPServer 在随机端口上启动 runHTTPServer，返回地址和退出结果
+func startHTTPServer(t *testing.T, ctx context.Context, handler http.Handler, shutdownTimeout time.Duration) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- runHTTPServer(ctx, &http.Server{Handler: handler}, listener, shutdownTimeout)
+	}()
+	return "http://" + listener.Addr().String(), done
+}
+
+func TestRunHTTPServerDrainsRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, done := startHTTPServer(t, ctx, handler, 5*time.Second)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(addr)
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{string(body), err}
+	}()
+
+	// 请求处理中收到退出信号
+	<-started
+	cancel()
+
+	if r := <-response; r.err != nil || r.body != "done" {
+		t.Errorf("处理中的请求没有完成: %q %v", r.body, r.err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("runHTTPServer 返回 %v", err)
+	}
+	if _, err := http.Get(addr); err == nil {
+		t.Error("关闭后仍然接受新连接")
+	}
+}
+
+func TestRunHTTPServerShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, done := startHTTPServer(t, ctx, handler, 50*time.Millisecond)
+
+	go http.Get(addr)
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("超过 shutdownTimeout 仍有请求未完成时应返回错误")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runHTTPServer 没有在 shutdownTimeout 后返回")
+	}
+}
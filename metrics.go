@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// Prometheus 指标，通过 GET /metrics 暴露
+//
+// 指标名称和标签是监控面板、告警规则依赖的接口，修改前需要同步更新面板和告警规则。
+//
+// HTTP（由 Metrics.Middleware 记录）:
+//
+//	http_requests_total{method,route,status}            请求数，route 为路由模板（例如 /orders/:id），未匹配的路由为 unmatched
+//	http_request_duration_seconds{method,route}         请求耗时直方图
+//	http_requests_in_flight                             正在处理的请求数
+//
+// 数据库（由 Metrics.InstrumentDB 注册的 GORM 回调和连接池采集器记录）:
+//
+//	db_query_duration_seconds{table,operation}          SQL 耗时直方图，operation 为 create、query、update、delete、row、raw
+//	db_query_errors_total{table,operation}              SQL 执行失败次数，查询不到数据不计入
+//	go_sql_open_connections{db_name}                    连接池当前打开的连接数（含使用中和空闲）
+//	go_sql_in_use_connections{db_name}                  使用中的连接数
+//	go_sql_idle_connections{db_name}                    空闲连接数
+//	go_sql_max_open_connections{db_name}                最大打开连接数
+//	go_sql_wait_count_total{db_name}                    等待空闲连接的总次数，持续增长说明连接池不足
+//	go_sql_wait_duration_seconds_total{db_name}         等待空闲连接的总时间
+//	go_sql_max_idle_closed_total{db_name} 等            连接池关闭连接的次数，见 collectors.NewDBStatsCollector
+//
+// 业务:
+//
+//	orders_created_total                                下单成功的订单数
+//	order_transitions_total{event}                      订单状态流转成功的次数，event 为 pay、ship、complete、cancel（含超时自动取消）
+//	orders_expired_total                                超时未支付被自动取消的订单数
+//	orders_by_status{status}                            各状态的订单数（采集时查询数据库），status 为 pending、paid、shipped、completed、cancelled
+//
+// 另外包含 Go 运行时（go_*）和进程（process_*）的标准指标。
+const (
+	metricRouteUnmatched = "unmatched"     // 未匹配任何路由的请求（404），避免把任意路径作为标签值
+	metricTableUnknown   = "unknown"       // 原生 SQL 等无法确定表名的语句
+	metricsScrapeTimeout = 3 * time.Second // 采集 orders_by_status 时查询数据库的超时时间
+)
+
+// orderStatusMetricNames 订单状态在指标标签中的名称
+var orderStatusMetricNames = map[int8]string{
+	OrderStatusPending:   "pending",
+	OrderStatusPaid:      "paid",
+	OrderStatusShipped:   "shipped",
+	OrderStatusCompleted: "completed",
+	OrderStatusCancelled: "cancelled",
+}
+
+// Metrics 应用的 Prometheus 指标
+// 每个 Metrics 使用独立的 Registry，测试中可以创建多个实例互不影响
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	httpInFlight    prometheus.Gauge
+	dbQueryDuration *prometheus.HistogramVec
+	dbQueryErrors   *prometheus.CounterVec
+
+	ordersCreated    prometheus.Counter
+	orderTransitions *prometheus.CounterVec
+	ordersExpired    prometheus.Counter
+}
+
+// NewMetrics 创建并注册所有指标
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP 请求数",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP 请求耗时（秒）",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "正在处理的 HTTP 请求数",
+		}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "SQL 执行耗时（秒）",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"table", "operation"}),
+		dbQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_query_errors_total",
+			Help: "SQL 执行失败次数",
+		}, []string{"table", "operation"}),
+		ordersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "orders_created_total",
+			Help: "下单成功的订单数",
+		}),
+		orderTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "order_transitions_total",
+			Help: "订单状态流转成功的次数",
+		}, []string{"event"}),
+		ordersExpired: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "orders_expired_total",
+			Help: "超时未支付被自动取消的订单数",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.httpInFlight,
+		m.dbQueryDuration, m.dbQueryErrors,
+		m.ordersCreated, m.orderTransitions, m.ordersExpired,
+	)
+	return m
+}
+
+// Handler 暴露指标的 handler
+// GET /metrics
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware 记录每个请求的次数和耗时
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.httpInFlight.Inc()
+		defer m.httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = metricRouteUnmatched
+		}
+		method := c.Request.Method
+		m.httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// OrderCreated 记录一笔下单成功的订单
+func (m *Metrics) OrderCreated() {
+	if m != nil {
+		m.ordersCreated.Inc()
+	}
+}
+
+// OrderTransitioned 记录一次成功的订单状态流转
+func (m *Metrics) OrderTransitioned(event OrderEvent) {
+	if m != nil {
+		m.orderTransitions.WithLabelValues(string(event)).Inc()
+	}
+}
+
+// OrdersExpired 记录超时未支付被自动取消的订单数
+func (m *Metrics) OrdersExpired(count int) {
+	if m != nil {
+		m.ordersExpired.Add(float64(count))
+	}
+}
+
+// InstrumentDB 注册 GORM 回调记录 SQL 耗时，并采集连接池状态和各状态的订单数
+func (m *Metrics) InstrumentDB(db *gorm.DB, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := m.registry.Register(collectors.NewDBStatsCollector(sqlDB, name)); err != nil {
+		return err
+	}
+	if err := m.registry.Register(&orderStatusCollector{db: db, desc: prometheus.NewDesc(
+		"orders_by_status", "各状态的订单数", []string{"status"}, nil)}); err != nil {
+		return err
+	}
+	return m.registerQueryCallbacks(db)
+}
+
+// metricsStartKey SQL 开始执行时间在 GORM Statement 中的键
+const metricsStartKey = "metrics:start"
+
+// registerQueryCallbacks 在 GORM 每类操作前后注册回调，记录 SQL 耗时和失败次数
+func (m *Metrics) registerQueryCallbacks(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(metricsStartKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(metricsStartKey)
+			if !ok {
+				return
+			}
+			start, _ := value.(time.Time)
+			table := tx.Statement.Table
+			if table == "" {
+				table = metricTableUnknown
+			}
+			m.dbQueryDuration.WithLabelValues(table, operation).Observe(time.Since(start).Seconds())
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				m.dbQueryErrors.WithLabelValues(table, operation).Inc()
+			}
+		}
+	}
+
+	// 在 gorm 内置的 create、query 等回调前后注册，只统计 SQL 本身的耗时
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("metrics:before_create", before),
+		callback.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", before),
+		callback.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", before),
+		callback.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", before),
+		callback.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	)
+}
+
+// orderStatusCollector 采集时查询各状态的订单数
+// 订单数来自数据库而不是进程内计数，多个实例和重启后结果一致
+type orderStatusCollector struct {
+	db   *gorm.DB
+	desc *prometheus.Desc
+}
+
+func (c *orderStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *orderStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsScrapeTimeout)
+	defer cancel()
+
+	var rows []struct {
+		Status int8
+		Count  int64
+	}
+	err := c.db.WithContext(ctx).Model(&Order{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, fmt.Errorf("查询订单状态统计失败: %v", err))
+		return
+	}
+
+	// 没有订单的状态也输出 0，便于告警规则和面板计算
+	counts := make(map[int8]int64, len(orderStatusMetricNames))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	for status, name := range orderStatusMetricNames {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts[status]), name)
+	}
+}
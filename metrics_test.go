@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// scrapeMetrics 请求 /metrics 并返回文本格式的指标
+func scrapeMetrics(t *testing.T, ts *testServer) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/metrics: 状态码 = %d", w.Code)
+	}
+	body, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureCaseID, Quantity: 1}}}
+	ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated)
+	ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/pay", fixturePendingOrderID), zhangsan, nil, http.StatusOK)
+	ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/ship", fixtureShippedOrderID), ts.login("operator"), nil, http.StatusConflict)
+	ts.expect(http.MethodGet, "/orders/999", zhangsan, nil, http.StatusNotFound)
+	ts.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/path", nil))
+
+	var counts []struct {
+		Status int8
+		Count  int
+	}
+	ts.db.Model(&Order{}).Select("status, COUNT(*) AS count").Group("status").Scan(&counts)
+	byStatus := make(map[int8]int)
+	for _, c := range counts {
+		byStatus[c.Status] = c.Count
+	}
+
+	body := scrapeMetrics(t, ts)
+	want := []string{
+		// 请求按路由模板统计，未匹配的路径不会产生新的标签值
+		`http_requests_total{method="POST",route="/orders",status="201"} 1`,
+		`http_requests_total{method="POST",route="/orders/:id/pay",status="200"} 1`,
+		`http_requests_total{method="GET",route="/orders/:id",status="404"} 1`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/orders"} 1`,
+		// 失败的流转不计入
+		"orders_created_total 1",
+		`order_transitions_total{event="pay"} 1`,
+		fmt.Sprintf(`orders_by_status{status="pending"} %d`, byStatus[OrderStatusPending]),
+		fmt.Sprintf(`orders_by_status{status="paid"} %d`, byStatus[OrderStatusPaid]),
+		`orders_by_status{status="cancelled"} 0`,
+		`go_sql_max_open_connections{db_name=":memory:"} 1`,
+		"go_sql_wait_count_total",
+	}
+	for _, line := range want {
+		if !strings.Contains(body, line) {
+			t.Errorf("指标中缺少 %s", line)
+		}
+	}
+	if strings.Contains(body, `order_transitions_total{event="ship"}`) {
+		t.Error("失败的状态流转不应计入 order_transitions_total")
+	}
+	for _, prefix := range []string{
+		`db_query_duration_seconds_count{operation="create",table="orders"}`,
+		`db_query_duration_seconds_count{operation="query",table="users"}`,
+		`db_query_duration_seconds_count{operation="update",table="products"}`,
+	} {
+		if !strings.Contains(body, prefix) {
+			t.Errorf("指标中缺少 %s", prefix)
+		}
+	}
+
+	// SQL 执行失败
+	ts.db.Table("no_such_table").Find(&[]Order{})
+	if body := scrapeMetrics(t, ts); !strings.Contains(body, `db_query_errors_total{operation="query",table="no_such_table"} 1`) {
+		t.Error("指标中缺少 SQL 执行失败次数")
+	}
+}
@@ -7,8 +7,27 @@ import (
 	"gorm.io/gorm"
 )
 
-// migrate 数据库迁移函数
-func migrate(db *gorm.DB) error {
+// autoMigrate 使用 GORM AutoMigrate 根据模型同步表结构
+// 仅用于本地开发（DB_AUTO_MIGRATE=true）：AutoMigrate 不会删除或重命名字段，也不记录迁移历史，
+// 生产环境请使用 migrations 目录下的版本化迁移
+func autoMigrate(db *gorm.DB) error {
+	// 分类表需要先于商品表创建，商品表的 category_id 外键依赖它
+	if err := db.AutoMigrate(&Category{}); err != nil {
+		return fmt.Errorf("数据库迁移失败: %v", err)
+	}
+
+	// 早期版本的 category_id 没有对应的分类表，创建外键前先清理指向不存在分类的数据
+	if db.Migrator().HasTable(&Product{}) {
+		result := db.Exec("UPDATE products SET category_id = NULL " +
+			"WHERE category_id IS NOT NULL AND category_id NOT IN (SELECT id FROM categories)")
+		if result.Error != nil {
+			return fmt.Errorf("清理商品分类数据失败: %v", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			fmt.Printf("✓ 已清空 %d 个商品指向不存在分类的 category_id\n", result.RowsAffected)
+		}
+	}
+
 	// 自动迁移所有模型
 	err := db.AutoMigrate(
 		&User{},
@@ -16,11 +35,21 @@ func migrate(db *gorm.DB) error {
 		&Product{},
 		&Order{},
 		&OrderItem{},
+		&StockReservation{},
+		&StockMovement{},
 	)
 	if err != nil {
 		return fmt.Errorf("数据库迁移失败: %v", err)
 	}
 
+	// 每个用户最多一个默认地址的部分唯一索引，GORM 的索引标签无法表达，与 0003 迁移保持一致；MySQL 不支持部分索引
+	if db.Dialector.Name() != DriverMySQL {
+		if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_user_default ON addresses (user_id) " +
+			"WHERE is_default = true AND deleted_at IS NULL").Error; err != nil {
+			return fmt.Errorf("创建默认地址唯一索引失败: %v", err)
+		}
+	}
+
 	fmt.Println("✓ 数据库表创建成功！")
 	return nil
 }
@@ -55,6 +84,12 @@ const (
 	ProductStatusOffSale int8 = 0 // 下架
 )
 
+// 分类状态常量
+const (
+	CategoryStatusEnabled  int8 = 1 // 启用
+	CategoryStatusDisabled int8 = 0 // 禁用
+)
+
 // generateProductNo 生成商品编号
 // 格式: PROD + 年月日 + 纳秒时间戳后8位 + 序号
 func generateProductNo(seq int) string {
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles 版本化迁移文件，按数据库类型分目录存放
+// 文件名格式: {版本号}_{名称}.up.sql / {版本号}_{名称}.down.sql，例如 0001_init_schema.up.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// SchemaMigration 迁移历史表，记录已执行的迁移版本和校验和
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false;comment:迁移版本号" json:"version"`
+	Name      string    `gorm:"type:varchar(200);not null;comment:迁移名称" json:"name"`
+	Checksum  string    `gorm:"type:varchar(64);not null;comment:迁移文件SHA-256校验和" json:"checksum"`
+	AppliedAt time.Time `gorm:"not null;comment:执行时间" json:"applied_at"`
+}
+
+// TableName 指定迁移历史表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration 一个版本化迁移
+type Migration struct {
+	Version  uint
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string // up 脚本的 SHA-256，用于发现已执行的迁移文件被修改
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   uint       `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"` // applied / pending / modified / missing
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// 迁移状态
+const (
+	MigrationStateApplied  = "applied"  // 已执行
+	MigrationStatePending  = "pending"  // 未执行
+	MigrationStateModified = "modified" // 已执行，但迁移文件在执行后被修改
+	MigrationStateMissing  = "missing"  // 已执行，但找不到对应的迁移文件
+)
+
+// ErrMigrationChecksum 已执行的迁移文件被修改
+var ErrMigrationChecksum = errors.New("迁移文件校验和不一致")
+
+// loadMigrations 读取指定数据库类型的迁移文件，按版本号升序返回
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的数据库类型 %s: 找不到迁移目录 %s", dialect, dir)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", fileName)
+		}
+		version, err := strconv.ParseUint(versionStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件版本号错误: %s", fileName)
+		}
+
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件失败: %v", err)
+		}
+
+		migration, exists := byVersion[uint(version)]
+		if !exists {
+			migration = &Migration{Version: uint(version), Name: name}
+			byVersion[uint(version)] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("迁移版本号重复: %04d (%s, %s)", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.UpSQL = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" {
+			return nil, fmt.Errorf("迁移 %04d_%s 缺少 up 脚本", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitSQLStatements 将迁移脚本拆分为单条语句
+// 以行尾的分号作为语句结束，忽略 "--" 开头的注释行（MySQL 驱动默认不允许一次执行多条语句）
+func splitSQLStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// ensureMigrationTable 创建迁移历史表
+func ensureMigrationTable(db *gorm.DB) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("创建迁移历史表失败: %v", err)
+	}
+	return nil
+}
+
+// appliedMigrations 查询已执行的迁移，按版本号升序返回
+func appliedMigrations(db *gorm.DB) ([]SchemaMigration, error) {
+	var applied []SchemaMigration
+	if err := db.Order("version ASC").Find(&applied).Error; err != nil {
+		return nil, fmt.Errorf("查询迁移历史失败: %v", err)
+	}
+	return applied, nil
+}
+
+// migrateUp 按版本号顺序执行未执行的迁移
+// steps 为 0 表示执行全部未执行的迁移；返回本次执行的迁移
+// 已执行的迁移文件如果被修改，拒绝继续执行，避免数据库结构与代码不一致
+func migrateUp(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedByVersion := make(map[uint]SchemaMigration, len(applied))
+	for _, record := range applied {
+		appliedByVersion[record.Version] = record
+	}
+
+	var executed []Migration
+	for _, migration := range migrations {
+		if record, ok := appliedByVersion[migration.Version]; ok {
+			if record.Checksum != migration.Checksum {
+				return executed, fmt.Errorf("%w: %04d_%s", ErrMigrationChecksum, migration.Version, migration.Name)
+			}
+			continue
+		}
+		if steps > 0 && len(executed) >= steps {
+			break
+		}
+
+		// 注意: MySQL 的 DDL 会隐式提交事务，包含多条 DDL 的迁移失败后可能只执行了一部分，
+		// 因此迁移脚本应尽量使用 IF NOT EXISTS / IF EXISTS 保证可以重复执行；
+		// PostgreSQL 和 SQLite 的 DDL 支持事务，失败时整个迁移回滚
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range splitSQLStatements(migration.UpSQL) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return executed, fmt.Errorf("执行迁移 %04d_%s 失败: %v", migration.Version, migration.Name, err)
+		}
+		executed = append(executed, migration)
+	}
+
+	return executed, nil
+}
+
+// migrateDown 回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func migrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("回滚步数必须大于 0")
+	}
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var rolledBack []Migration
+	for i := len(applied) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		record := applied[i]
+		migration, ok := byVersion[record.Version]
+		if !ok {
+			return rolledBack, fmt.Errorf("找不到迁移 %04d_%s 的迁移文件，无法回滚", record.Version, record.Name)
+		}
+		if migration.DownSQL == "" {
+			return rolledBack, fmt.Errorf("迁移 %04d_%s 没有 down 脚本，无法回滚", migration.Version, migration.Name)
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, statement := range splitSQLStatements(migration.DownSQL) {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Delete(&SchemaMigration{}, record.Version).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("回滚迁移 %04d_%s 失败: %v", migration.Version, migration.Name, err)
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+
+	return rolledBack, nil
+}
+
+// migrationStatus 返回所有迁移的执行状态，按版本号升序
+// 只读查询，就绪探针每次都会调用：迁移历史表不存在时视为所有迁移都未执行，不创建该表
+func migrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	var applied []SchemaMigration
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		if applied, err = appliedMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+
+	appliedByVersion := make(map[uint]SchemaMigration, len(applied))
+	for _, record := range applied {
+		appliedByVersion[record.Version] = record
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	known := make(map[uint]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, State: MigrationStatePending}
+		if record, ok := appliedByVersion[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = MigrationStateApplied
+			if record.Checksum != migration.Checksum {
+				status.State = MigrationStateModified
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		if !known[record.Version] {
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, MigrationStatus{
+				Version:   record.Version,
+				Name:      record.Name,
+				State:     MigrationStateMissing,
+				AppliedAt: &appliedAt,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// runMigrateCommand 执行迁移命令，返回进程退出码
+//
+//	migrate up [N]    执行未执行的迁移（默认全部）
+//	migrate down [N]  回滚最近的 N 个迁移（默认 1 个）
+//	migrate status    查看迁移状态
+func runMigrateCommand(db *gorm.DB, args []string) int {
+	if len(args) == 0 {
+		fmt.Println("用法: migrate up [N] | migrate down [N] | migrate status")
+		return exitUsage
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			fmt.Printf("无效的步数: %s\n", args[1])
+			return exitUsage
+		}
+		steps = n
+	}
+
+	switch args[0] {
+	case "up":
+		executed, err := migrateUp(db, steps)
+		for _, migration := range executed {
+			fmt.Printf("✓ 已执行迁移 %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return exitFailure
+		}
+		if len(executed) == 0 {
+			fmt.Println("✓ 数据库已是最新版本")
+		}
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+		rolledBack, err := migrateDown(db, steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("✓ 已回滚迁移 %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return exitFailure
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("✓ 没有可回滚的迁移")
+		}
+	case "status":
+		statuses, err := migrationStatus(db)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return exitFailure
+		}
+		fmt.Printf("%-8s %-40s %-10s %s\n", "VERSION", "NAME", "STATE", "APPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d     %-40s %-10s %s\n", status.Version, status.Name, status.State, appliedAt)
+		}
+	default:
+		fmt.Printf("未知的迁移命令: %s\n", args[0])
+		return exitUsage
+	}
+
+	return exitOK
+}
@@ -0,0 +1,129 @@
+-- 初始表结构
+-- 使用 IF NOT EXISTS，已经通过 AutoMigrate 建好表的数据库执行本迁移时不会报错，只会记录版本
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '用户ID',
+  `username` varchar(50) NOT NULL COMMENT '用户名',
+  `phone` varchar(20) DEFAULT NULL COMMENT '手机号',
+  `email` varchar(100) DEFAULT NULL COMMENT '邮箱',
+  `password` varchar(255) NOT NULL COMMENT '密码(加密)',
+  `nickname` varchar(50) DEFAULT NULL COMMENT '昵称',
+  `avatar` varchar(255) DEFAULT NULL COMMENT '头像URL',
+  `status` tinyint DEFAULT 1 COMMENT '状态(1:正常 0:禁用)',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_users_username` (`username`),
+  UNIQUE KEY `idx_users_phone` (`phone`),
+  UNIQUE KEY `idx_users_email` (`email`),
+  KEY `idx_users_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `addresses` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '地址ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `receiver_name` varchar(50) NOT NULL COMMENT '收货人姓名',
+  `receiver_phone` varchar(20) NOT NULL COMMENT '收货人电话',
+  `province` varchar(50) NOT NULL COMMENT '省份',
+  `city` varchar(50) NOT NULL COMMENT '城市',
+  `district` varchar(50) NOT NULL COMMENT '区/县',
+  `detail` varchar(255) NOT NULL COMMENT '详细地址',
+  `postal_code` varchar(10) DEFAULT NULL COMMENT '邮政编码',
+  `is_default` tinyint(1) DEFAULT 0 COMMENT '是否默认地址(1:是 0:否)',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_addresses_user_id` (`user_id`),
+  KEY `idx_addresses_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_users_addresses` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `categories` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '分类ID',
+  `parent_id` bigint unsigned DEFAULT NULL COMMENT '上级分类ID(NULL表示顶级分类)',
+  `name` varchar(50) NOT NULL COMMENT '分类名称',
+  `sort` int DEFAULT 0 COMMENT '排序',
+  `status` tinyint DEFAULT 1 COMMENT '状态(1:启用 0:禁用)',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_categories_parent_id` (`parent_id`),
+  KEY `idx_categories_status` (`status`),
+  KEY `idx_categories_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_categories_parent` FOREIGN KEY (`parent_id`) REFERENCES `categories` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `products` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '商品ID',
+  `product_no` varchar(50) NOT NULL COMMENT '商品编号',
+  `name` varchar(200) NOT NULL COMMENT '商品名称',
+  `description` text COMMENT '商品描述',
+  `category_id` bigint unsigned DEFAULT NULL COMMENT '分类ID',
+  `price` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '商品价格',
+  `stock` int DEFAULT 0 COMMENT '库存数量',
+  `sales` int DEFAULT 0 COMMENT '销量',
+  `image` varchar(500) DEFAULT NULL COMMENT '商品主图',
+  `images` text COMMENT '商品图片(JSON数组)',
+  `status` tinyint DEFAULT 1 COMMENT '状态(1:上架 0:下架)',
+  `sort` int DEFAULT 0 COMMENT '排序',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_products_product_no` (`product_no`),
+  KEY `idx_products_name` (`name`),
+  KEY `idx_products_category_id` (`category_id`),
+  KEY `idx_products_status` (`status`),
+  KEY `idx_products_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_products_category` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `orders` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订单ID',
+  `order_no` varchar(32) NOT NULL COMMENT '订单号',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `address_id` bigint unsigned NOT NULL COMMENT '收货地址ID',
+  `total_amount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '订单总金额',
+  `discount_amount` decimal(10,2) DEFAULT 0.00 COMMENT '优惠金额',
+  `pay_amount` decimal(10,2) NOT NULL DEFAULT 0.00 COMMENT '实付金额',
+  `status` tinyint DEFAULT 0 COMMENT '订单状态(0:待支付 1:已支付 2:已发货 3:已完成 4:已取消)',
+  `pay_method` varchar(20) DEFAULT NULL COMMENT '支付方式',
+  `pay_time` datetime(3) DEFAULT NULL COMMENT '支付时间',
+  `ship_time` datetime(3) DEFAULT NULL COMMENT '发货时间',
+  `complete_time` datetime(3) DEFAULT NULL COMMENT '完成时间',
+  `remark` varchar(500) DEFAULT NULL COMMENT '订单备注',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_orders_order_no` (`order_no`),
+  KEY `idx_orders_user_id` (`user_id`),
+  KEY `idx_orders_address_id` (`address_id`),
+  KEY `idx_orders_status` (`status`),
+  KEY `idx_orders_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_users_orders` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`),
+  CONSTRAINT `fk_addresses_orders` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '明细ID',
+  `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `product_name` varchar(200) NOT NULL COMMENT '商品名称(快照)',
+  `product_image` varchar(500) DEFAULT NULL COMMENT '商品图片(快照)',
+  `price` decimal(10,2) NOT NULL COMMENT '商品单价(快照)',
+  `quantity` int NOT NULL DEFAULT 1 COMMENT '购买数量',
+  `subtotal` decimal(10,2) NOT NULL COMMENT '小计金额',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) DEFAULT NULL COMMENT '删除时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_order_items_order_id` (`order_id`),
+  KEY `idx_order_items_product_id` (`product_id`),
+  KEY `idx_order_items_deleted_at` (`deleted_at`),
+  CONSTRAINT `fk_orders_order_items` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`),
+  CONSTRAINT `fk_products_order_items` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
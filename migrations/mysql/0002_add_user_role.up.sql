@@ -0,0 +1,5 @@
+-- 用户角色，已有用户默认为顾客
+
+ALTER TABLE `users`
+  ADD COLUMN `role` varchar(20) NOT NULL DEFAULT 'customer' COMMENT '角色(customer:顾客 operator:运营 admin:管理员)' AFTER `status`,
+  ADD KEY `idx_users_role` (`role`);
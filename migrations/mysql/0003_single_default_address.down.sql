@@ -0,0 +1 @@
+-- 数据修正无法回滚，回滚时只删除迁移记录
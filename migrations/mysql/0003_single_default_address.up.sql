@@ -0,0 +1,11 @@
+-- 修正历史数据：每个用户最多保留一个默认地址（保留最近创建的一个）
+
+UPDATE `addresses` a
+JOIN (
+  SELECT `user_id`, MAX(`id`) AS `keep_id`
+  FROM `addresses`
+  WHERE `is_default` = 1 AND `deleted_at` IS NULL
+  GROUP BY `user_id`
+) d ON a.`user_id` = d.`user_id`
+SET a.`is_default` = 0
+WHERE a.`is_default` = 1 AND a.`id` <> d.`keep_id`;
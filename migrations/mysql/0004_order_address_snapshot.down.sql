@@ -0,0 +1,8 @@
+ALTER TABLE `orders`
+  DROP COLUMN `receiver_name`,
+  DROP COLUMN `receiver_phone`,
+  DROP COLUMN `province`,
+  DROP COLUMN `city`,
+  DROP COLUMN `district`,
+  DROP COLUMN `address_detail`,
+  DROP COLUMN `postal_code`;
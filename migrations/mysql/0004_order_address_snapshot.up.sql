@@ -0,0 +1,23 @@
+-- 订单收货地址快照
+-- 下单时将收货地址复制到订单上，之后修改或删除地址不影响历史订单
+
+ALTER TABLE `orders`
+  ADD COLUMN `receiver_name` varchar(50) NOT NULL DEFAULT '' COMMENT '收货人姓名(快照)' AFTER `address_id`,
+  ADD COLUMN `receiver_phone` varchar(20) NOT NULL DEFAULT '' COMMENT '收货人电话(快照)' AFTER `receiver_name`,
+  ADD COLUMN `province` varchar(50) NOT NULL DEFAULT '' COMMENT '省份(快照)' AFTER `receiver_phone`,
+  ADD COLUMN `city` varchar(50) NOT NULL DEFAULT '' COMMENT '城市(快照)' AFTER `province`,
+  ADD COLUMN `district` varchar(50) NOT NULL DEFAULT '' COMMENT '区/县(快照)' AFTER `city`,
+  ADD COLUMN `address_detail` varchar(255) NOT NULL DEFAULT '' COMMENT '详细地址(快照)' AFTER `district`,
+  ADD COLUMN `postal_code` varchar(10) NOT NULL DEFAULT '' COMMENT '邮政编码(快照)' AFTER `address_detail`;
+
+-- 回填历史订单：使用地址表中的当前数据（包括已软删除的地址），这是迁移时能拿到的最接近下单时的地址
+UPDATE `orders` o
+JOIN `addresses` a ON a.`id` = o.`address_id`
+SET o.`receiver_name` = a.`receiver_name`,
+    o.`receiver_phone` = a.`receiver_phone`,
+    o.`province` = a.`province`,
+    o.`city` = a.`city`,
+    o.`district` = a.`district`,
+    o.`address_detail` = a.`detail`,
+    o.`postal_code` = COALESCE(a.`postal_code`, '')
+WHERE o.`receiver_name` = '';
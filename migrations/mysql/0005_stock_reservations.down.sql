@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `stock_reservations`;
+ALTER TABLE `products` DROP COLUMN `reserved_stock`;
@@ -0,0 +1,22 @@
+-- 库存预占
+-- 下单时预占库存（reserved_stock），支付时转为扣减 stock，取消或超时未支付时释放
+-- 之前的订单在下单时已经扣减了库存，没有预占记录，取消时按原来的方式归还库存
+
+ALTER TABLE `products`
+  ADD COLUMN `reserved_stock` int NOT NULL DEFAULT 0 COMMENT '预占库存(待支付订单占用)' AFTER `stock`;
+
+CREATE TABLE IF NOT EXISTS `stock_reservations` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '预占ID',
+  `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `quantity` int NOT NULL COMMENT '预占数量',
+  `status` tinyint NOT NULL DEFAULT 0 COMMENT '状态(0:已预占 1:已扣减 2:已释放)',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) DEFAULT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_stock_reservations_order_product` (`order_id`, `product_id`),
+  KEY `idx_stock_reservations_product_id` (`product_id`),
+  KEY `idx_stock_reservations_status` (`status`),
+  CONSTRAINT `fk_stock_reservations_order` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`),
+  CONSTRAINT `fk_stock_reservations_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,28 @@
+-- 库存流水
+-- 记录商品库存、预占数量、销量的每一次变动（下单、支付、取消、人工调整、进货、退货），只追加不修改
+
+CREATE TABLE IF NOT EXISTS `stock_movements` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '流水ID',
+  `product_id` bigint unsigned NOT NULL COMMENT '商品ID',
+  `order_id` bigint unsigned DEFAULT NULL COMMENT '关联订单ID',
+  `operator_id` bigint unsigned DEFAULT NULL COMMENT '操作人ID(NULL表示系统自动执行)',
+  `type` varchar(20) NOT NULL COMMENT '变动类型',
+  `quantity` int NOT NULL COMMENT '变动数量',
+  `stock_before` int NOT NULL COMMENT '变动前库存',
+  `stock_after` int NOT NULL COMMENT '变动后库存',
+  `reserved_before` int NOT NULL COMMENT '变动前预占库存',
+  `reserved_after` int NOT NULL COMMENT '变动后预占库存',
+  `sales_before` int NOT NULL COMMENT '变动前销量',
+  `sales_after` int NOT NULL COMMENT '变动后销量',
+  `reason` varchar(255) NOT NULL DEFAULT '' COMMENT '变动原因',
+  `created_at` datetime(3) DEFAULT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_stock_movements_product_id` (`product_id`),
+  KEY `idx_stock_movements_order_id` (`order_id`),
+  KEY `idx_stock_movements_operator_id` (`operator_id`),
+  KEY `idx_stock_movements_type` (`type`),
+  KEY `idx_stock_movements_created_at` (`created_at`),
+  CONSTRAINT `fk_stock_movements_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`),
+  CONSTRAINT `fk_stock_movements_order` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`),
+  CONSTRAINT `fk_stock_movements_operator` FOREIGN KEY (`operator_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
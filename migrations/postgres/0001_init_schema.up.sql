@@ -0,0 +1,210 @@
+-- 初始表结构（PostgreSQL）
+-- 使用 IF NOT EXISTS，已经通过 AutoMigrate 建好表的数据库执行本迁移时不会报错，只会记录版本
+-- PostgreSQL 没有列级 COMMENT 语法，表和字段说明通过 COMMENT ON 设置
+
+CREATE TABLE IF NOT EXISTS users (
+  id bigserial,
+  username varchar(50) NOT NULL,
+  phone varchar(20),
+  email varchar(100),
+  password varchar(255) NOT NULL,
+  nickname varchar(50),
+  avatar varchar(255),
+  status smallint DEFAULT 1,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+COMMENT ON TABLE users IS '用户表';
+COMMENT ON COLUMN users.id IS '用户ID';
+COMMENT ON COLUMN users.username IS '用户名';
+COMMENT ON COLUMN users.phone IS '手机号';
+COMMENT ON COLUMN users.email IS '邮箱';
+COMMENT ON COLUMN users.password IS '密码(加密)';
+COMMENT ON COLUMN users.nickname IS '昵称';
+COMMENT ON COLUMN users.avatar IS '头像URL';
+COMMENT ON COLUMN users.status IS '状态(1:正常 0:禁用)';
+COMMENT ON COLUMN users.created_at IS '创建时间';
+COMMENT ON COLUMN users.updated_at IS '更新时间';
+COMMENT ON COLUMN users.deleted_at IS '删除时间';
+
+CREATE TABLE IF NOT EXISTS addresses (
+  id bigserial,
+  user_id bigint NOT NULL,
+  receiver_name varchar(50) NOT NULL,
+  receiver_phone varchar(20) NOT NULL,
+  province varchar(50) NOT NULL,
+  city varchar(50) NOT NULL,
+  district varchar(50) NOT NULL,
+  detail varchar(255) NOT NULL,
+  postal_code varchar(10),
+  is_default boolean DEFAULT false,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_users_addresses FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_addresses_user_id ON addresses (user_id);
+CREATE INDEX IF NOT EXISTS idx_addresses_deleted_at ON addresses (deleted_at);
+COMMENT ON TABLE addresses IS '收货地址表';
+COMMENT ON COLUMN addresses.id IS '地址ID';
+COMMENT ON COLUMN addresses.user_id IS '用户ID';
+COMMENT ON COLUMN addresses.receiver_name IS '收货人姓名';
+COMMENT ON COLUMN addresses.receiver_phone IS '收货人电话';
+COMMENT ON COLUMN addresses.province IS '省份';
+COMMENT ON COLUMN addresses.city IS '城市';
+COMMENT ON COLUMN addresses.district IS '区/县';
+COMMENT ON COLUMN addresses.detail IS '详细地址';
+COMMENT ON COLUMN addresses.postal_code IS '邮政编码';
+COMMENT ON COLUMN addresses.is_default IS '是否默认地址';
+COMMENT ON COLUMN addresses.created_at IS '创建时间';
+COMMENT ON COLUMN addresses.updated_at IS '更新时间';
+COMMENT ON COLUMN addresses.deleted_at IS '删除时间';
+
+CREATE TABLE IF NOT EXISTS categories (
+  id bigserial,
+  parent_id bigint,
+  name varchar(50) NOT NULL,
+  sort integer DEFAULT 0,
+  status smallint DEFAULT 1,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_status ON categories (status);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+COMMENT ON TABLE categories IS '商品分类表';
+COMMENT ON COLUMN categories.id IS '分类ID';
+COMMENT ON COLUMN categories.parent_id IS '上级分类ID(NULL表示顶级分类)';
+COMMENT ON COLUMN categories.name IS '分类名称';
+COMMENT ON COLUMN categories.sort IS '排序';
+COMMENT ON COLUMN categories.status IS '状态(1:启用 0:禁用)';
+COMMENT ON COLUMN categories.created_at IS '创建时间';
+COMMENT ON COLUMN categories.updated_at IS '更新时间';
+COMMENT ON COLUMN categories.deleted_at IS '删除时间';
+
+CREATE TABLE IF NOT EXISTS products (
+  id bigserial,
+  product_no varchar(50) NOT NULL,
+  name varchar(200) NOT NULL,
+  description text,
+  category_id bigint,
+  price decimal(10,2) NOT NULL DEFAULT 0.00,
+  stock integer DEFAULT 0,
+  sales integer DEFAULT 0,
+  image varchar(500),
+  images text,
+  status smallint DEFAULT 1,
+  sort integer DEFAULT 0,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_product_no ON products (product_no);
+CREATE INDEX IF NOT EXISTS idx_products_name ON products (name);
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id);
+CREATE INDEX IF NOT EXISTS idx_products_status ON products (status);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+COMMENT ON TABLE products IS '商品表';
+COMMENT ON COLUMN products.id IS '商品ID';
+COMMENT ON COLUMN products.product_no IS '商品编号';
+COMMENT ON COLUMN products.name IS '商品名称';
+COMMENT ON COLUMN products.description IS '商品描述';
+COMMENT ON COLUMN products.category_id IS '分类ID';
+COMMENT ON COLUMN products.price IS '商品价格';
+COMMENT ON COLUMN products.stock IS '库存数量';
+COMMENT ON COLUMN products.sales IS '销量';
+COMMENT ON COLUMN products.image IS '商品主图';
+COMMENT ON COLUMN products.images IS '商品图片(JSON数组)';
+COMMENT ON COLUMN products.status IS '状态(1:上架 0:下架)';
+COMMENT ON COLUMN products.sort IS '排序';
+COMMENT ON COLUMN products.created_at IS '创建时间';
+COMMENT ON COLUMN products.updated_at IS '更新时间';
+COMMENT ON COLUMN products.deleted_at IS '删除时间';
+
+CREATE TABLE IF NOT EXISTS orders (
+  id bigserial,
+  order_no varchar(32) NOT NULL,
+  user_id bigint NOT NULL,
+  address_id bigint NOT NULL,
+  total_amount decimal(10,2) NOT NULL DEFAULT 0.00,
+  discount_amount decimal(10,2) DEFAULT 0.00,
+  pay_amount decimal(10,2) NOT NULL DEFAULT 0.00,
+  status smallint DEFAULT 0,
+  pay_method varchar(20),
+  pay_time timestamptz,
+  ship_time timestamptz,
+  complete_time timestamptz,
+  remark varchar(500),
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_users_orders FOREIGN KEY (user_id) REFERENCES users (id),
+  CONSTRAINT fk_addresses_orders FOREIGN KEY (address_id) REFERENCES addresses (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_order_no ON orders (order_no);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+CREATE INDEX IF NOT EXISTS idx_orders_address_id ON orders (address_id);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+COMMENT ON TABLE orders IS '订单表';
+COMMENT ON COLUMN orders.id IS '订单ID';
+COMMENT ON COLUMN orders.order_no IS '订单号';
+COMMENT ON COLUMN orders.user_id IS '用户ID';
+COMMENT ON COLUMN orders.address_id IS '收货地址ID';
+COMMENT ON COLUMN orders.total_amount IS '订单总金额';
+COMMENT ON COLUMN orders.discount_amount IS '优惠金额';
+COMMENT ON COLUMN orders.pay_amount IS '实付金额';
+COMMENT ON COLUMN orders.status IS '订单状态(0:待支付 1:已支付 2:已发货 3:已完成 4:已取消)';
+COMMENT ON COLUMN orders.pay_method IS '支付方式';
+COMMENT ON COLUMN orders.pay_time IS '支付时间';
+COMMENT ON COLUMN orders.ship_time IS '发货时间';
+COMMENT ON COLUMN orders.complete_time IS '完成时间';
+COMMENT ON COLUMN orders.remark IS '订单备注';
+COMMENT ON COLUMN orders.created_at IS '创建时间';
+COMMENT ON COLUMN orders.updated_at IS '更新时间';
+COMMENT ON COLUMN orders.deleted_at IS '删除时间';
+
+CREATE TABLE IF NOT EXISTS order_items (
+  id bigserial,
+  order_id bigint NOT NULL,
+  product_id bigint NOT NULL,
+  product_name varchar(200) NOT NULL,
+  product_image varchar(500),
+  price decimal(10,2) NOT NULL,
+  quantity integer NOT NULL DEFAULT 1,
+  subtotal decimal(10,2) NOT NULL,
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_orders_order_items FOREIGN KEY (order_id) REFERENCES orders (id),
+  CONSTRAINT fk_products_order_items FOREIGN KEY (product_id) REFERENCES products (id)
+);
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items (product_id);
+CREATE INDEX IF NOT EXISTS idx_order_items_deleted_at ON order_items (deleted_at);
+COMMENT ON TABLE order_items IS '订单明细表';
+COMMENT ON COLUMN order_items.id IS '明细ID';
+COMMENT ON COLUMN order_items.order_id IS '订单ID';
+COMMENT ON COLUMN order_items.product_id IS '商品ID';
+COMMENT ON COLUMN order_items.product_name IS '商品名称(快照)';
+COMMENT ON COLUMN order_items.product_image IS '商品图片(快照)';
+COMMENT ON COLUMN order_items.price IS '商品单价(快照)';
+COMMENT ON COLUMN order_items.quantity IS '购买数量';
+COMMENT ON COLUMN order_items.subtotal IS '小计金额';
+COMMENT ON COLUMN order_items.created_at IS '创建时间';
+COMMENT ON COLUMN order_items.updated_at IS '更新时间';
+COMMENT ON COLUMN order_items.deleted_at IS '删除时间';
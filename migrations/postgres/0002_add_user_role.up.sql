@@ -0,0 +1,5 @@
+-- 用户角色，已有用户默认为顾客
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'customer';
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role);
+COMMENT ON COLUMN users.role IS '角色(customer:顾客 operator:运营 admin:管理员)';
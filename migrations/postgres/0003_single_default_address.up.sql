@@ -0,0 +1,14 @@
+-- 修正历史数据：每个用户最多保留一个默认地址（保留最近创建的一个）
+
+UPDATE addresses a
+SET is_default = false
+FROM (
+  SELECT user_id, MAX(id) AS keep_id
+  FROM addresses
+  WHERE is_default AND deleted_at IS NULL
+  GROUP BY user_id
+) d
+WHERE a.user_id = d.user_id AND a.is_default AND a.id <> d.keep_id;
+
+-- 每个用户最多一个未删除的默认地址，并发设置默认地址时由数据库兜底
+CREATE UNIQUE INDEX idx_addresses_user_default ON addresses (user_id) WHERE is_default AND deleted_at IS NULL;
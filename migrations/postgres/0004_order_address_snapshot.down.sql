@@ -0,0 +1,8 @@
+ALTER TABLE orders
+  DROP COLUMN IF EXISTS receiver_name,
+  DROP COLUMN IF EXISTS receiver_phone,
+  DROP COLUMN IF EXISTS province,
+  DROP COLUMN IF EXISTS city,
+  DROP COLUMN IF EXISTS district,
+  DROP COLUMN IF EXISTS address_detail,
+  DROP COLUMN IF EXISTS postal_code;
@@ -0,0 +1,30 @@
+-- 订单收货地址快照
+-- 下单时将收货地址复制到订单上，之后修改或删除地址不影响历史订单
+
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS receiver_name varchar(50) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS receiver_phone varchar(20) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS province varchar(50) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS city varchar(50) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS district varchar(50) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS address_detail varchar(255) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS postal_code varchar(10) NOT NULL DEFAULT '';
+COMMENT ON COLUMN orders.receiver_name IS '收货人姓名(快照)';
+COMMENT ON COLUMN orders.receiver_phone IS '收货人电话(快照)';
+COMMENT ON COLUMN orders.province IS '省份(快照)';
+COMMENT ON COLUMN orders.city IS '城市(快照)';
+COMMENT ON COLUMN orders.district IS '区/县(快照)';
+COMMENT ON COLUMN orders.address_detail IS '详细地址(快照)';
+COMMENT ON COLUMN orders.postal_code IS '邮政编码(快照)';
+
+-- 回填历史订单：使用地址表中的当前数据（包括已软删除的地址），这是迁移时能拿到的最接近下单时的地址
+UPDATE orders o
+SET receiver_name = a.receiver_name,
+    receiver_phone = a.receiver_phone,
+    province = a.province,
+    city = a.city,
+    district = a.district,
+    address_detail = a.detail,
+    postal_code = COALESCE(a.postal_code, '')
+FROM addresses a
+WHERE a.id = o.address_id AND o.receiver_name = '';
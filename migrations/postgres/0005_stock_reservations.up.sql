@@ -0,0 +1,30 @@
+-- 库存预占
+-- 下单时预占库存（reserved_stock），支付时转为扣减 stock，取消或超时未支付时释放
+-- 之前的订单在下单时已经扣减了库存，没有预占记录，取消时按原来的方式归还库存
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reserved_stock integer NOT NULL DEFAULT 0;
+COMMENT ON COLUMN products.reserved_stock IS '预占库存(待支付订单占用)';
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+  id bigserial,
+  order_id bigint NOT NULL,
+  product_id bigint NOT NULL,
+  quantity integer NOT NULL,
+  status smallint NOT NULL DEFAULT 0,
+  created_at timestamptz,
+  updated_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_stock_reservations_order FOREIGN KEY (order_id) REFERENCES orders (id),
+  CONSTRAINT fk_stock_reservations_product FOREIGN KEY (product_id) REFERENCES products (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_reservations_order_product ON stock_reservations (order_id, product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_product_id ON stock_reservations (product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_status ON stock_reservations (status);
+COMMENT ON TABLE stock_reservations IS '库存预占表';
+COMMENT ON COLUMN stock_reservations.id IS '预占ID';
+COMMENT ON COLUMN stock_reservations.order_id IS '订单ID';
+COMMENT ON COLUMN stock_reservations.product_id IS '商品ID';
+COMMENT ON COLUMN stock_reservations.quantity IS '预占数量';
+COMMENT ON COLUMN stock_reservations.status IS '状态(0:已预占 1:已扣减 2:已释放)';
+COMMENT ON COLUMN stock_reservations.created_at IS '创建时间';
+COMMENT ON COLUMN stock_reservations.updated_at IS '更新时间';
@@ -0,0 +1,43 @@
+-- 库存流水
+-- 记录商品库存、预占数量、销量的每一次变动（下单、支付、取消、人工调整、进货、退货），只追加不修改
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id bigserial,
+  product_id bigint NOT NULL,
+  order_id bigint,
+  operator_id bigint,
+  type varchar(20) NOT NULL,
+  quantity integer NOT NULL,
+  stock_before integer NOT NULL,
+  stock_after integer NOT NULL,
+  reserved_before integer NOT NULL,
+  reserved_after integer NOT NULL,
+  sales_before integer NOT NULL,
+  sales_after integer NOT NULL,
+  reason varchar(255) NOT NULL DEFAULT '',
+  created_at timestamptz,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products (id),
+  CONSTRAINT fk_stock_movements_order FOREIGN KEY (order_id) REFERENCES orders (id),
+  CONSTRAINT fk_stock_movements_operator FOREIGN KEY (operator_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements (product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_order_id ON stock_movements (order_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_operator_id ON stock_movements (operator_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_type ON stock_movements (type);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created_at ON stock_movements (created_at);
+COMMENT ON TABLE stock_movements IS '库存流水表';
+COMMENT ON COLUMN stock_movements.id IS '流水ID';
+COMMENT ON COLUMN stock_movements.product_id IS '商品ID';
+COMMENT ON COLUMN stock_movements.order_id IS '关联订单ID';
+COMMENT ON COLUMN stock_movements.operator_id IS '操作人ID(NULL表示系统自动执行)';
+COMMENT ON COLUMN stock_movements.type IS '变动类型';
+COMMENT ON COLUMN stock_movements.quantity IS '变动数量';
+COMMENT ON COLUMN stock_movements.stock_before IS '变动前库存';
+COMMENT ON COLUMN stock_movements.stock_after IS '变动后库存';
+COMMENT ON COLUMN stock_movements.reserved_before IS '变动前预占库存';
+COMMENT ON COLUMN stock_movements.reserved_after IS '变动后预占库存';
+COMMENT ON COLUMN stock_movements.sales_before IS '变动前销量';
+COMMENT ON COLUMN stock_movements.sales_after IS '变动后销量';
+COMMENT ON COLUMN stock_movements.reason IS '变动原因';
+COMMENT ON COLUMN stock_movements.created_at IS '创建时间';
@@ -0,0 +1,8 @@
+-- 回滚初始表结构（会删除所有数据）
+
+DROP TABLE IF EXISTS `order_items`;
+DROP TABLE IF EXISTS `orders`;
+DROP TABLE IF EXISTS `products`;
+DROP TABLE IF EXISTS `categories`;
+DROP TABLE IF EXISTS `addresses`;
+DROP TABLE IF EXISTS `users`;
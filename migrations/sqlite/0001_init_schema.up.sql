@@ -0,0 +1,123 @@
+-- 初始表结构（SQLite，用于本地开发和测试）
+-- SQLite 没有列注释，字段含义见 models.go 和 migrations/mysql 下的同名迁移
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `username` varchar(50) NOT NULL,
+  `phone` varchar(20),
+  `email` varchar(100),
+  `password` varchar(255) NOT NULL,
+  `nickname` varchar(50),
+  `avatar` varchar(255),
+  `status` tinyint DEFAULT 1,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_username` ON `users` (`username`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_phone` ON `users` (`phone`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email` ON `users` (`email`);
+CREATE INDEX IF NOT EXISTS `idx_users_deleted_at` ON `users` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `addresses` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `user_id` integer NOT NULL,
+  `receiver_name` varchar(50) NOT NULL,
+  `receiver_phone` varchar(20) NOT NULL,
+  `province` varchar(50) NOT NULL,
+  `city` varchar(50) NOT NULL,
+  `district` varchar(50) NOT NULL,
+  `detail` varchar(255) NOT NULL,
+  `postal_code` varchar(10),
+  `is_default` tinyint(1) DEFAULT 0,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_users_addresses` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_addresses_user_id` ON `addresses` (`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_addresses_deleted_at` ON `addresses` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `categories` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `parent_id` integer,
+  `name` varchar(50) NOT NULL,
+  `sort` int DEFAULT 0,
+  `status` tinyint DEFAULT 1,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_categories_parent` FOREIGN KEY (`parent_id`) REFERENCES `categories` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_categories_parent_id` ON `categories` (`parent_id`);
+CREATE INDEX IF NOT EXISTS `idx_categories_status` ON `categories` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_categories_deleted_at` ON `categories` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `products` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `product_no` varchar(50) NOT NULL,
+  `name` varchar(200) NOT NULL,
+  `description` text,
+  `category_id` integer,
+  `price` decimal(10,2) NOT NULL DEFAULT 0.00,
+  `stock` int DEFAULT 0,
+  `sales` int DEFAULT 0,
+  `image` varchar(500),
+  `images` text,
+  `status` tinyint DEFAULT 1,
+  `sort` int DEFAULT 0,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_products_category` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_products_product_no` ON `products` (`product_no`);
+CREATE INDEX IF NOT EXISTS `idx_products_name` ON `products` (`name`);
+CREATE INDEX IF NOT EXISTS `idx_products_category_id` ON `products` (`category_id`);
+CREATE INDEX IF NOT EXISTS `idx_products_status` ON `products` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_products_deleted_at` ON `products` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `orders` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `order_no` varchar(32) NOT NULL,
+  `user_id` integer NOT NULL,
+  `address_id` integer NOT NULL,
+  `total_amount` decimal(10,2) NOT NULL DEFAULT 0.00,
+  `discount_amount` decimal(10,2) DEFAULT 0.00,
+  `pay_amount` decimal(10,2) NOT NULL DEFAULT 0.00,
+  `status` tinyint DEFAULT 0,
+  `pay_method` varchar(20),
+  `pay_time` datetime,
+  `ship_time` datetime,
+  `complete_time` datetime,
+  `remark` varchar(500),
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_users_orders` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`),
+  CONSTRAINT `fk_addresses_orders` FOREIGN KEY (`address_id`) REFERENCES `addresses` (`id`)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_orders_order_no` ON `orders` (`order_no`);
+CREATE INDEX IF NOT EXISTS `idx_orders_user_id` ON `orders` (`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_orders_address_id` ON `orders` (`address_id`);
+CREATE INDEX IF NOT EXISTS `idx_orders_status` ON `orders` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_orders_deleted_at` ON `orders` (`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `order_id` integer NOT NULL,
+  `product_id` integer NOT NULL,
+  `product_name` varchar(200) NOT NULL,
+  `product_image` varchar(500),
+  `price` decimal(10,2) NOT NULL,
+  `quantity` int NOT NULL DEFAULT 1,
+  `subtotal` decimal(10,2) NOT NULL,
+  `created_at` datetime,
+  `updated_at` datetime,
+  `deleted_at` datetime,
+  CONSTRAINT `fk_orders_order_items` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`),
+  CONSTRAINT `fk_products_order_items` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_order_items_order_id` ON `order_items` (`order_id`);
+CREATE INDEX IF NOT EXISTS `idx_order_items_product_id` ON `order_items` (`product_id`);
+CREATE INDEX IF NOT EXISTS `idx_order_items_deleted_at` ON `order_items` (`deleted_at`);
@@ -0,0 +1,4 @@
+-- 用户角色，已有用户默认为顾客
+
+ALTER TABLE `users` ADD COLUMN `role` varchar(20) NOT NULL DEFAULT 'customer';
+CREATE INDEX IF NOT EXISTS `idx_users_role` ON `users` (`role`);
@@ -0,0 +1,3 @@
+-- 数据修正无法回滚，回滚时只删除唯一索引和迁移记录
+
+DROP INDEX IF EXISTS `idx_addresses_user_default`;
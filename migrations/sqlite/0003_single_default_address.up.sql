@@ -0,0 +1,13 @@
+-- 修正历史数据：每个用户最多保留一个默认地址（保留最近创建的一个）
+
+UPDATE `addresses`
+SET `is_default` = 0
+WHERE `is_default` = 1
+  AND `id` <> (
+    SELECT MAX(d.`id`)
+    FROM `addresses` d
+    WHERE d.`user_id` = `addresses`.`user_id` AND d.`is_default` = 1 AND d.`deleted_at` IS NULL
+  );
+
+-- 每个用户最多一个未删除的默认地址，并发设置默认地址时由数据库兜底
+CREATE UNIQUE INDEX `idx_addresses_user_default` ON `addresses` (`user_id`) WHERE `is_default` = 1 AND `deleted_at` IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE `orders` DROP COLUMN `receiver_name`;
+ALTER TABLE `orders` DROP COLUMN `receiver_phone`;
+ALTER TABLE `orders` DROP COLUMN `province`;
+ALTER TABLE `orders` DROP COLUMN `city`;
+ALTER TABLE `orders` DROP COLUMN `district`;
+ALTER TABLE `orders` DROP COLUMN `address_detail`;
+ALTER TABLE `orders` DROP COLUMN `postal_code`;
@@ -0,0 +1,20 @@
+-- 订单收货地址快照
+-- 下单时将收货地址复制到订单上，之后修改或删除地址不影响历史订单
+
+ALTER TABLE `orders` ADD COLUMN `receiver_name` varchar(50) NOT NULL DEFAULT '';
+ALTER TABLE `orders` ADD COLUMN `receiver_phone` varchar(20) NOT NULL DEFAULT '';
+ALTER TABLE `orders` ADD COLUMN `province` varchar(50) NOT NULL DEFAULT '';
+ALTER TABLE `orders` ADD COLUMN `city` varchar(50) NOT NULL DEFAULT '';
+ALTER TABLE `orders` ADD COLUMN `district` varchar(50) NOT NULL DEFAULT '';
+ALTER TABLE `orders` ADD COLUMN `address_detail` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `orders` ADD COLUMN `postal_code` varchar(10) NOT NULL DEFAULT '';
+
+-- 回填历史订单：使用地址表中的当前数据（包括已软删除的地址）
+UPDATE `orders`
+SET (`receiver_name`, `receiver_phone`, `province`, `city`, `district`, `address_detail`, `postal_code`) = (
+    SELECT a.`receiver_name`, a.`receiver_phone`, a.`province`, a.`city`, a.`district`, a.`detail`, COALESCE(a.`postal_code`, '')
+    FROM `addresses` a
+    WHERE a.`id` = `orders`.`address_id`
+  )
+WHERE `receiver_name` = ''
+  AND EXISTS (SELECT 1 FROM `addresses` a WHERE a.`id` = `orders`.`address_id`);
@@ -0,0 +1,20 @@
+-- 库存预占
+-- 下单时预占库存（reserved_stock），支付时转为扣减 stock，取消或超时未支付时释放
+-- 之前的订单在下单时已经扣减了库存，没有预占记录，取消时按原来的方式归还库存
+
+ALTER TABLE `products` ADD COLUMN `reserved_stock` int NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS `stock_reservations` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `order_id` integer NOT NULL,
+  `product_id` integer NOT NULL,
+  `quantity` int NOT NULL,
+  `status` tinyint NOT NULL DEFAULT 0,
+  `created_at` datetime,
+  `updated_at` datetime,
+  CONSTRAINT `fk_stock_reservations_order` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`),
+  CONSTRAINT `fk_stock_reservations_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_stock_reservations_order_product` ON `stock_reservations` (`order_id`, `product_id`);
+CREATE INDEX IF NOT EXISTS `idx_stock_reservations_product_id` ON `stock_reservations` (`product_id`);
+CREATE INDEX IF NOT EXISTS `idx_stock_reservations_status` ON `stock_reservations` (`status`);
@@ -0,0 +1,27 @@
+-- 库存流水
+-- 记录商品库存、预占数量、销量的每一次变动（下单、支付、取消、人工调整、进货、退货），只追加不修改
+
+CREATE TABLE IF NOT EXISTS `stock_movements` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `product_id` integer NOT NULL,
+  `order_id` integer,
+  `operator_id` integer,
+  `type` varchar(20) NOT NULL,
+  `quantity` int NOT NULL,
+  `stock_before` int NOT NULL,
+  `stock_after` int NOT NULL,
+  `reserved_before` int NOT NULL,
+  `reserved_after` int NOT NULL,
+  `sales_before` int NOT NULL,
+  `sales_after` int NOT NULL,
+  `reason` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime,
+  CONSTRAINT `fk_stock_movements_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`),
+  CONSTRAINT `fk_stock_movements_order` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`),
+  CONSTRAINT `fk_stock_movements_operator` FOREIGN KEY (`operator_id`) REFERENCES `users` (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_stock_movements_product_id` ON `stock_movements` (`product_id`);
+CREATE INDEX IF NOT EXISTS `idx_stock_movements_order_id` ON `stock_movements` (`order_id`);
+CREATE INDEX IF NOT EXISTS `idx_stock_movements_operator_id` ON `stock_movements` (`operator_id`);
+CREATE INDEX IF NOT EXISTS `idx_stock_movements_type` ON `stock_movements` (`type`);
+CREATE INDEX IF NOT EXISTS `idx_stock_movements_created_at` ON `stock_movements` (`created_at`);
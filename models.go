@@ -6,6 +6,11 @@ import (
 	"gorm.io/gorm"
 )
 
+// 模型标签需要同时适用于 MySQL、PostgreSQL 和 SQLite：
+//   - 状态字段使用 int8，由 GORM 按数据库映射为 tinyint（MySQL）或 smallint（PostgreSQL），不要写死 type:tinyint
+//   - 布尔字段使用 bool，映射为 tinyint(1)（MySQL）或 boolean（PostgreSQL），默认值写 false 而不是 0
+//   - decimal、varchar、text 三种数据库都支持；comment 在 PostgreSQL 下生成 COMMENT ON，SQLite 忽略
+
 // User 用户表
 type User struct {
 	ID        uint           `gorm:"primaryKey;autoIncrement;comment:用户ID" json:"id"`
@@ -15,7 +20,8 @@ type User struct {
 	Password  string         `gorm:"type:varchar(255);not null;comment:密码(加密)" json:"-"`
 	Nickname  string         `gorm:"type:varchar(50);comment:昵称" json:"nickname"`
 	Avatar    string         `gorm:"type:varchar(255);comment:头像URL" json:"avatar"`
-	Status    int8           `gorm:"type:tinyint;default:1;comment:状态(1:正常 0:禁用)" json:"status"`
+	Status    int8           `gorm:"default:1;comment:状态(1:正常 0:禁用)" json:"status"`
+	Role      string         `gorm:"type:varchar(20);not null;default:customer;index;comment:角色(customer:顾客 operator:运营 admin:管理员)" json:"role"`
 	CreatedAt time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`
@@ -36,7 +42,7 @@ type Address struct {
 	District      string         `gorm:"type:varchar(50);not null;comment:区/县" json:"district"`
 	Detail        string         `gorm:"type:varchar(255);not null;comment:详细地址" json:"detail"`
 	PostalCode    string         `gorm:"type:varchar(10);comment:邮政编码" json:"postal_code"`
-	IsDefault     bool           `gorm:"type:tinyint(1);default:0;comment:是否默认地址(1:是 0:否)" json:"is_default"`
+	IsDefault     bool           `gorm:"default:false;comment:是否默认地址" json:"is_default"`
 	CreatedAt     time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`
@@ -52,10 +58,17 @@ type Order struct {
 	OrderNo        string         `gorm:"type:varchar(32);uniqueIndex;not null;comment:订单号" json:"order_no"`
 	UserID         uint           `gorm:"not null;index;comment:用户ID" json:"user_id"`
 	AddressID      uint           `gorm:"not null;index;comment:收货地址ID" json:"address_id"`
-	TotalAmount    float64        `gorm:"type:decimal(10,2);not null;default:0.00;comment:订单总金额" json:"total_amount"`
-	DiscountAmount float64        `gorm:"type:decimal(10,2);default:0.00;comment:优惠金额" json:"discount_amount"`
-	PayAmount      float64        `gorm:"type:decimal(10,2);not null;default:0.00;comment:实付金额" json:"pay_amount"`
-	Status         int8           `gorm:"type:tinyint;default:0;index;comment:订单状态(0:待支付 1:已支付 2:已发货 3:已完成 4:已取消)" json:"status"`
+	ReceiverName   string         `gorm:"type:varchar(50);not null;default:'';comment:收货人姓名(快照)" json:"receiver_name"`
+	ReceiverPhone  string         `gorm:"type:varchar(20);not null;default:'';comment:收货人电话(快照)" json:"receiver_phone"`
+	Province       string         `gorm:"type:varchar(50);not null;default:'';comment:省份(快照)" json:"province"`
+	City           string         `gorm:"type:varchar(50);not null;default:'';comment:城市(快照)" json:"city"`
+	District       string         `gorm:"type:varchar(50);not null;default:'';comment:区/县(快照)" json:"district"`
+	AddressDetail  string         `gorm:"type:varchar(255);not null;default:'';comment:详细地址(快照)" json:"address_detail"`
+	PostalCode     string         `gorm:"type:varchar(10);not null;default:'';comment:邮政编码(快照)" json:"postal_code"`
+	TotalAmount    Money          `gorm:"type:decimal(10,2);not null;default:0.00;comment:订单总金额" json:"total_amount"`
+	DiscountAmount Money          `gorm:"type:decimal(10,2);default:0.00;comment:优惠金额" json:"discount_amount"`
+	PayAmount      Money          `gorm:"type:decimal(10,2);not null;default:0.00;comment:实付金额" json:"pay_amount"`
+	Status         int8           `gorm:"default:0;index;comment:订单状态(0:待支付 1:已支付 2:已发货 3:已完成 4:已取消)" json:"status"`
 	PayMethod      string         `gorm:"type:varchar(20);comment:支付方式" json:"pay_method"`
 	PayTime        *time.Time     `gorm:"comment:支付时间" json:"pay_time"`
 	ShipTime       *time.Time     `gorm:"comment:发货时间" json:"ship_time"`
@@ -73,26 +86,44 @@ type Order struct {
 
 // Product 商品表
 type Product struct {
-	ID          uint           `gorm:"primaryKey;autoIncrement;comment:商品ID" json:"id"`
-	ProductNo   string         `gorm:"type:varchar(50);uniqueIndex;not null;comment:商品编号" json:"product_no"`
-	Name        string         `gorm:"type:varchar(200);not null;index;comment:商品名称" json:"name"`
-	Description string         `gorm:"type:text;comment:商品描述" json:"description"`
-	CategoryID  uint           `gorm:"index;comment:分类ID" json:"category_id"`
-	Price       float64        `gorm:"type:decimal(10,2);not null;default:0.00;comment:商品价格" json:"price"`
-	Stock       int            `gorm:"type:int;default:0;comment:库存数量" json:"stock"`
-	Sales       int            `gorm:"type:int;default:0;comment:销量" json:"sales"`
-	Image       string         `gorm:"type:varchar(500);comment:商品主图" json:"image"`
-	Images      string         `gorm:"type:text;comment:商品图片(JSON数组)" json:"images"`
-	Status      int8           `gorm:"type:tinyint;default:1;index;comment:状态(1:上架 0:下架)" json:"status"`
-	Sort        int            `gorm:"type:int;default:0;comment:排序" json:"sort"`
-	CreatedAt   time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
-	UpdatedAt   time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`
+	ID            uint           `gorm:"primaryKey;autoIncrement;comment:商品ID" json:"id"`
+	ProductNo     string         `gorm:"type:varchar(50);uniqueIndex;not null;comment:商品编号" json:"product_no"`
+	Name          string         `gorm:"type:varchar(200);not null;index;comment:商品名称" json:"name"`
+	Description   string         `gorm:"type:text;comment:商品描述" json:"description"`
+	CategoryID    *uint          `gorm:"index;comment:分类ID" json:"category_id"`
+	Price         Money          `gorm:"type:decimal(10,2);not null;default:0.00;comment:商品价格" json:"price"`
+	Stock         int            `gorm:"type:int;default:0;comment:库存数量" json:"stock"`
+	Sales         int            `gorm:"type:int;default:0;comment:销量" json:"sales"`
+	ReservedStock int            `gorm:"type:int;not null;default:0;comment:预占库存(待支付订单占用)" json:"reserved_stock"`
+	Image         string         `gorm:"type:varchar(500);comment:商品主图" json:"image"`
+	Images        string         `gorm:"type:text;comment:商品图片(JSON数组)" json:"images"`
+	Status        int8           `gorm:"default:1;index;comment:状态(1:上架 0:下架)" json:"status"`
+	Sort          int            `gorm:"type:int;default:0;comment:排序" json:"sort"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`
 
 	// 关联关系
+	Category   *Category   `gorm:"foreignKey:CategoryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"category,omitempty"`
 	OrderItems []OrderItem `gorm:"foreignKey:ProductID;references:ID" json:"order_items,omitempty"`
 }
 
+// Category 商品分类表（支持多级分类）
+type Category struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement;comment:分类ID" json:"id"`
+	ParentID  *uint          `gorm:"index;comment:上级分类ID(NULL表示顶级分类)" json:"parent_id"`
+	Name      string         `gorm:"type:varchar(50);not null;comment:分类名称" json:"name"`
+	Sort      int            `gorm:"type:int;default:0;comment:排序" json:"sort"`
+	Status    int8           `gorm:"default:1;index;comment:状态(1:启用 0:禁用)" json:"status"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`
+
+	// 关联关系
+	Parent   *Category  `gorm:"foreignKey:ParentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"` // 隐藏反向关联，避免 JSON 输出冗余
+	Children []Category `gorm:"-" json:"children,omitempty"`                                                              // 分类树中的子分类，由代码组装，不对应数据库字段
+}
+
 // OrderItem 订单商品明细表
 type OrderItem struct {
 	ID           uint           `gorm:"primaryKey;autoIncrement;comment:明细ID" json:"id"`
@@ -100,9 +131,9 @@ type OrderItem struct {
 	ProductID    uint           `gorm:"not null;index;comment:商品ID" json:"product_id"`
 	ProductName  string         `gorm:"type:varchar(200);not null;comment:商品名称(快照)" json:"product_name"`
 	ProductImage string         `gorm:"type:varchar(500);comment:商品图片(快照)" json:"product_image"`
-	Price        float64        `gorm:"type:decimal(10,2);not null;comment:商品单价(快照)" json:"price"`
+	Price        Money          `gorm:"type:decimal(10,2);not null;comment:商品单价(快照)" json:"price"`
 	Quantity     int            `gorm:"type:int;not null;default:1;comment:购买数量" json:"quantity"`
-	Subtotal     float64        `gorm:"type:decimal(10,2);not null;comment:小计金额" json:"subtotal"`
+	Subtotal     Money          `gorm:"type:decimal(10,2);not null;comment:小计金额" json:"subtotal"`
 	CreatedAt    time.Time      `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index;comment:删除时间" json:"-"`
@@ -111,3 +142,42 @@ type OrderItem struct {
 	Order   Order   `gorm:"foreignKey:OrderID;references:ID" json:"-"`   // 隐藏反向关联，避免 JSON 输出冗余
 	Product Product `gorm:"foreignKey:ProductID;references:ID" json:"-"` // 隐藏反向关联，避免 JSON 输出冗余
 }
+
+// StockReservation 库存预占表
+// 下单时为每个商品预占库存，支付时转为扣减，取消或超时未支付时释放
+type StockReservation struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement;comment:预占ID" json:"id"`
+	OrderID   uint      `gorm:"not null;uniqueIndex:idx_stock_reservations_order_product;comment:订单ID" json:"order_id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_stock_reservations_order_product;index;comment:商品ID" json:"product_id"`
+	Quantity  int       `gorm:"type:int;not null;comment:预占数量" json:"quantity"`
+	Status    int8      `gorm:"not null;default:0;index;comment:状态(0:已预占 1:已扣减 2:已释放)" json:"status"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;comment:更新时间" json:"updated_at"`
+
+	// 关联关系
+	Order   Order   `gorm:"foreignKey:OrderID;references:ID" json:"-"`   // 隐藏反向关联，避免 JSON 输出冗余
+	Product Product `gorm:"foreignKey:ProductID;references:ID" json:"-"` // 隐藏反向关联，避免 JSON 输出冗余
+}
+
+// StockMovement 库存流水表，记录商品库存、预占数量、销量的每一次变动，只追加不修改
+type StockMovement struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement;comment:流水ID" json:"id"`
+	ProductID      uint      `gorm:"not null;index;comment:商品ID" json:"product_id"`
+	OrderID        *uint     `gorm:"index;comment:关联订单ID" json:"order_id"`
+	OperatorID     *uint     `gorm:"index;comment:操作人ID(NULL表示系统自动执行)" json:"operator_id"`
+	Type           string    `gorm:"type:varchar(20);not null;index;comment:变动类型" json:"type"`
+	Quantity       int       `gorm:"type:int;not null;comment:变动数量" json:"quantity"`
+	StockBefore    int       `gorm:"type:int;not null;comment:变动前库存" json:"stock_before"`
+	StockAfter     int       `gorm:"type:int;not null;comment:变动后库存" json:"stock_after"`
+	ReservedBefore int       `gorm:"type:int;not null;comment:变动前预占库存" json:"reserved_before"`
+	ReservedAfter  int       `gorm:"type:int;not null;comment:变动后预占库存" json:"reserved_after"`
+	SalesBefore    int       `gorm:"type:int;not null;comment:变动前销量" json:"sales_before"`
+	SalesAfter     int       `gorm:"type:int;not null;comment:变动后销量" json:"sales_after"`
+	Reason         string    `gorm:"type:varchar(255);not null;default:'';comment:变动原因" json:"reason"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index;comment:创建时间" json:"created_at"`
+
+	// 关联关系
+	Product  Product `gorm:"foreignKey:ProductID;references:ID" json:"-"`  // 隐藏反向关联，避免 JSON 输出冗余
+	Order    *Order  `gorm:"foreignKey:OrderID;references:ID" json:"-"`    // 隐藏反向关联，避免 JSON 输出冗余
+	Operator *User   `gorm:"foreignKey:OperatorID;references:ID" json:"-"` // 隐藏反向关联，避免 JSON 输出冗余
+}
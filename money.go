@@ -0,0 +1,204 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money 金额类型
+// 以"分"为单位的整数保存，避免 float64 计算金额时的精度误差
+// 数据库中仍然映射为 decimal(10,2)，JSON 中输出为固定两位小数的字符串，例如 "7999.00"
+//
+// 使用结构体而不是 int64 的别名类型：GORM 对整数类型的字段会直接用 strconv.ParseInt
+// 回填 RETURNING/默认值，遇到 "0.00" 这样的 decimal 字符串会失败，结构体类型则统一走 Scan
+type Money struct {
+	fen int64
+}
+
+// Yuan 以元为单位构造金额，例如 Yuan(7999) 表示 7999.00 元
+func Yuan(n int64) Money {
+	return Money{fen: n * 100}
+}
+
+// Fen 以分为单位构造金额，例如 Fen(1) 表示 0.01 元
+func Fen(n int64) Money {
+	return Money{fen: n}
+}
+
+// ParseMoney 解析十进制金额字符串，例如 "7999"、"7999.5"、"-0.01"
+// 超过两位的小数按四舍五入处理（数据库 AVG 等聚合结果可能带有更多小数位）
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, fmt.Errorf("金额不能为空")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return Money{}, fmt.Errorf("无效的金额: %q", s)
+	}
+	// 符号只能出现一次，strconv.ParseInt 会接受去掉符号后剩下的 "+5"、"-5"
+	if strings.HasPrefix(intPart, "+") || strings.HasPrefix(intPart, "-") {
+		return Money{}, fmt.Errorf("无效的金额: %q", s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	yuan, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || yuan < 0 {
+		return Money{}, fmt.Errorf("无效的金额: %q", s)
+	}
+	for _, r := range fracPart {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("无效的金额: %q", s)
+		}
+	}
+
+	// 取前两位小数作为"分"，第三位小数决定是否进位
+	fen := int64(0)
+	for i := 0; i < 2; i++ {
+		fen *= 10
+		if i < len(fracPart) {
+			fen += int64(fracPart[i] - '0')
+		}
+	}
+	if len(fracPart) > 2 && fracPart[2] >= '5' {
+		fen++
+	}
+
+	amount := yuan*100 + fen
+	if negative {
+		amount = -amount
+	}
+	return Fen(amount), nil
+}
+
+// MoneyFromFloat 将浮点数金额转换为 Money，按分四舍五入
+// 仅用于兼容数据库驱动返回浮点数的情况，业务计算不应使用浮点数
+func MoneyFromFloat(f float64) Money {
+	return Fen(int64(math.Round(f * 100)))
+}
+
+// Fen 返回以分为单位的金额
+func (m Money) Fen() int64 {
+	return m.fen
+}
+
+// IsZero 金额是否为 0
+func (m Money) IsZero() bool {
+	return m.fen == 0
+}
+
+// Add 金额相加
+func (m Money) Add(other Money) Money {
+	return Fen(m.fen + other.fen)
+}
+
+// Sub 金额相减
+func (m Money) Sub(other Money) Money {
+	return Fen(m.fen - other.fen)
+}
+
+// Mul 金额乘以数量
+func (m Money) Mul(n int) Money {
+	return Fen(m.fen * int64(n))
+}
+
+// Div 金额除以数量，结果按分四舍五入
+func (m Money) Div(n int) Money {
+	if n == 0 {
+		return Money{}
+	}
+	d := int64(n)
+	q, r := m.fen/d, m.fen%d
+	if r*2 >= d {
+		q++
+	} else if r*2 <= -d {
+		q--
+	}
+	return Fen(q)
+}
+
+// String 返回固定两位小数的金额字符串，例如 "7999.00"
+func (m Money) String() string {
+	sign := ""
+	v := m.fen
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// MarshalJSON 序列化为固定两位小数的字符串
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + m.String() + `"`), nil
+}
+
+// UnmarshalJSON 同时兼容字符串 "7999.00" 和数字 7999.00 两种格式
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	amount, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// Scan 实现 sql.Scanner 接口，从数据库读取 decimal 字段
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		amount, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = amount
+		return nil
+	case string:
+		amount, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = amount
+		return nil
+	case int64:
+		*m = Yuan(v)
+		return nil
+	case float64:
+		*m = MoneyFromFloat(v)
+		return nil
+	default:
+		return fmt.Errorf("无法将 %T 转换为金额", value)
+	}
+}
+
+// Value 实现 driver.Valuer 接口，以十进制字符串写入数据库，避免精度丢失
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{"7999", Yuan(7999)},
+		{"7999.5", Fen(799950)},
+		{"7999.00", Yuan(7999)},
+		{" 12.34 ", Fen(1234)},
+		{"+12.34", Fen(1234)},
+		{".5", Fen(50)},
+		{"3.", Yuan(3)},
+		{"0", Money{}},
+		{"-0.01", Fen(-1)},
+		{"-7999", Yuan(-7999)},
+		// 第三位小数四舍五入，负数按绝对值舍入
+		{"1.004", Fen(100)},
+		{"1.005", Fen(101)},
+		{"1.0049999", Fen(100)},
+		{"1.999", Yuan(2)},
+		{"-1.004", Fen(-100)},
+		{"-1.005", Fen(-101)},
+		{"-0.005", Fen(-1)},
+		{"2666.6666666666666667", Fen(266667)},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+		if err != nil {
+			t.Errorf("ParseMoney(%q) 返回错误: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %s，期望 %s", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", " ", "-", ".", "abc", "1.2x", "1,000", "--1", "1e3", "1.-5", "-+5", "++5", "+-5", "-+.5"} {
+		if got, err := ParseMoney(input); err == nil {
+			t.Errorf("ParseMoney(%q) = %s，期望返回错误", input, got)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  Money
+	}{
+		{"nil", nil, Money{}},
+		{"[]byte", []byte("7999.00"), Yuan(7999)},
+		{"[]byte 聚合结果", []byte("2666.6666"), Fen(266667)},
+		{"string", "0.01", Fen(1)},
+		{"string 负数", "-99.50", Fen(-9950)},
+		{"int64", int64(15), Yuan(15)},
+		{"float64", 1899.99, Fen(189999)},
+		{"float64 精度误差", 0.1 + 0.2, Fen(30)},
+	}
+	for _, tt := range tests {
+		m := Yuan(1) // 确认 Scan 会覆盖原值
+		if err := m.Scan(tt.value); err != nil {
+			t.Errorf("%s: Scan 返回错误: %v", tt.name, err)
+			continue
+		}
+		if m != tt.want {
+			t.Errorf("%s: Scan(%v) = %s，期望 %s", tt.name, tt.value, m, tt.want)
+		}
+	}
+
+	var m Money
+	for _, value := range []interface{}{[]byte("abc"), "1.2.3", true, int32(1)} {
+		if err := m.Scan(value); err == nil {
+			t.Errorf("Scan(%#v) 期望返回错误", value)
+		}
+	}
+}
+
+func TestMoneyValue(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{}, "0.00"},
+		{Fen(1), "0.01"},
+		{Fen(-1), "-0.01"},
+		{Fen(799950), "7999.50"},
+		{Yuan(-14999), "-14999.00"},
+	}
+	for _, tt := range tests {
+		value, err := tt.money.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != tt.want {
+			t.Errorf("Value() = %#v，期望 %q", value, tt.want)
+		}
+
+		// 写入数据库的值可以原样读回
+		var scanned Money
+		if err := scanned.Scan(value); err != nil || scanned != tt.money {
+			t.Errorf("Scan(%#v) = %s, %v，期望 %s", value, scanned, err, tt.money)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type payload struct {
+		Amount Money  `json:"amount"`
+		Refund *Money `json:"refund"`
+	}
+
+	refund := Fen(-50)
+	data, err := json.Marshal(payload{Amount: Fen(799950), Refund: &refund})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":"7999.50","refund":"-0.50"}` {
+		t.Errorf("序列化结果 = %s", data)
+	}
+
+	var got payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount != Fen(799950) || got.Refund == nil || *got.Refund != refund {
+		t.Errorf("反序列化结果 = %+v", got)
+	}
+
+	// 同时兼容数字格式，null 保持原值
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{`{"amount":7999.5}`, Fen(799950)},
+		{`{"amount":0.015}`, Fen(2)},
+		{`{"amount":"12"}`, Yuan(12)},
+		{`{"amount":null}`, Yuan(1)},
+	}
+	for _, tt := range tests {
+		got := payload{Amount: Yuan(1)}
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if got.Amount != tt.want {
+			t.Errorf("%s: amount = %s，期望 %s", tt.input, got.Amount, tt.want)
+		}
+	}
+	for _, input := range []string{`{"amount":"abc"}`, `{"amount":true}`, `{"amount":""}`} {
+		var got payload
+		if err := json.Unmarshal([]byte(input), &got); err == nil {
+			t.Errorf("%s: 期望返回错误", input)
+		}
+	}
+}
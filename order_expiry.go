@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// orderExpiryBatchSize 每次查询的超时订单数，超时订单较多时分批取消
+const orderExpiryBatchSize = 100
+
+// expirePendingOrders 取消下单时间早于 before 的待支付订单并释放预占的库存，返回取消的订单数
+// 每个订单在各自的事务中取消，查询之后才支付的订单状态已改变，会被跳过
+func expirePendingOrders(ctx context.Context, db *gorm.DB, before time.Time) (int, error) {
+	expired := 0
+	for {
+		var ids []uint
+		if err := db.WithContext(ctx).Model(&Order{}).
+			Where("status = ? AND created_at < ?", OrderStatusPending, before).
+			Order("id").Limit(orderExpiryBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return expired, fmt.Errorf("查询超时未支付的订单失败: %v", err)
+		}
+
+		for _, id := range ids {
+			if _, err := transitionOrder(db.WithContext(ctx), id, OrderEventCancel, "", 0); err != nil {
+				if errors.Is(err, ErrIllegalTransition) || errors.Is(err, ErrOrderNotFound) {
+					continue
+				}
+				return expired, fmt.Errorf("取消订单 %d 失败: %w", id, err)
+			}
+			expired++
+		}
+		if len(ids) < orderExpiryBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// startOrderExpiry 启动后台任务，每隔 config.ExpiryInterval 取消超过 config.PendingTTL 未支付的订单
+// ctx 取消后任务退出，返回的 channel 在任务退出后关闭；PendingTTL 为 0 时不启动
+func startOrderExpiry(ctx context.Context, db *gorm.DB, config OrderConfig, metrics *Metrics) <-chan struct{} {
+	done := make(chan struct{})
+	if config.PendingTTL <= 0 {
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.ExpiryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				expired, err := expirePendingOrders(ctx, db, now.Add(-config.PendingTTL))
+				for i := 0; i < expired; i++ {
+					metrics.OrderTransitioned(OrderEventCancel)
+				}
+				metrics.OrdersExpired(expired)
+				if expired > 0 {
+					slog.Info("已自动取消超时未支付的订单", "count", expired, "pending_ttl", config.PendingTTL.String())
+				}
+				if err != nil && ctx.Err() == nil {
+					slog.Error("自动取消超时订单失败", "error", err)
+				}
+			}
+		}
+	}()
+	return done
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpirePendingOrders(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	place := func(quantity int) Order {
+		req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureMacBookID, Quantity: quantity}}}
+		var order Order
+		ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated).decode(t, &order)
+		return order
+	}
+	// 一小时前下单的两个订单，其中一个已支付；刚下单的订单还没有超时
+	expired, paid, fresh := place(1), place(2), place(3)
+	ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/pay", paid.ID), zhangsan, nil, http.StatusOK)
+	ts.db.Model(&Order{}).Where("id IN ?", []uint{expired.ID, paid.ID}).
+		Update("created_at", time.Now().Add(-time.Hour))
+
+	count, err := expirePendingOrders(context.Background(), ts.db, time.Now().Add(-30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("取消了 %d 个订单，期望 1", count)
+	}
+
+	want := map[uint]int8{expired.ID: OrderStatusCancelled, paid.ID: OrderStatusPaid, fresh.ID: OrderStatusPending}
+	for id, status := range want {
+		var order Order
+		ts.db.First(&order, id)
+		if order.Status != status {
+			t.Errorf("订单 %d 状态 = %s，期望 %s", id, orderStatusText[order.Status], orderStatusText[status])
+		}
+	}
+	var product Product
+	ts.db.First(&product, fixtureMacBookID)
+	if product.Stock != 48 || product.ReservedStock != 3 || product.Sales != 2 {
+		t.Errorf("库存 = %d，预占 = %d，销量 = %d，期望 48、3、2", product.Stock, product.ReservedStock, product.Sales)
+	}
+
+	// 再次执行没有需要取消的订单
+	if count, err := expirePendingOrders(context.Background(), ts.db, time.Now().Add(-30*time.Minute)); err != nil || count != 0 {
+		t.Errorf("再次执行取消了 %d 个订单: %v", count, err)
+	}
+}
+
+func TestStartOrderExpiry(t *testing.T) {
+	ts := newTestServer(t)
+	ts.db.Model(&Order{}).Where("id = ?", fixturePendingOrderID).
+		Update("created_at", time.Now().Add(-time.Hour))
+
+	// pending_ttl 为 0 时不启动
+	disabled := startOrderExpiry(context.Background(), ts.db, OrderConfig{}, nil)
+	select {
+	case <-disabled:
+	default:
+		t.Error("pending_ttl 为 0 时不应启动后台任务")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := startOrderExpiry(ctx, ts.db, OrderConfig{PendingTTL: 30 * time.Minute, ExpiryInterval: 10 * time.Millisecond}, ts.metrics)
+	deadline := time.Now().Add(5 * time.Second)
+	var order Order
+	for time.Now().Before(deadline) {
+		ts.db.First(&order, fixturePendingOrderID)
+		if order.Status == OrderStatusCancelled {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if order.Status != OrderStatusCancelled {
+		t.Fatalf("超时订单没有被自动取消: %s", orderStatusText[order.Status])
+	}
+	body := scrapeMetrics(t, ts)
+	for _, want := range []string{"orders_expired_total 1", `order_transitions_total{event="cancel"} 1`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("指标中缺少 %s", want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// 下单相关的业务错误，handler 根据错误类型返回对应的 HTTP 状态码
+var (
+	ErrUserNotFound      = errors.New("用户不存在")
+	ErrAddressNotFound   = errors.New("收货地址不存在")
+	ErrAddressNotOwned   = errors.New("收货地址不属于该用户")
+	ErrProductNotFound   = errors.New("商品不存在")
+	ErrProductOffSale    = errors.New("商品已下架")
+	ErrInsufficientStock = errors.New("商品库存不足")
+)
+
+// CreateOrderRequest 下单请求参数
+type CreateOrderRequest struct {
+	UserID     uint                     `json:"user_id"` // 下单用户，未传时为当前登录用户
+	OperatorID uint                     `json:"-"`       // 执行下单的用户（管理员可以为其他用户下单），记录在库存流水中
+	AddressID  uint                     `json:"address_id" binding:"required"`
+	Items      []CreateOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+	Remark     string                   `json:"remark" binding:"max=500"`
+}
+
+// CreateOrderItemRequest 下单商品参数
+type CreateOrderItemRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+	Quantity  int  `json:"quantity" binding:"required,min=1"`
+}
+
+// placeOrder 创建订单
+// 在一个事务中完成：校验用户和地址 -> 校验商品 -> 快照商品信息 -> 计算金额 -> 写入订单及明细 -> 预占库存
+// 任意一步失败都会回滚整个事务
+func placeOrder(db *gorm.DB, req CreateOrderRequest) (*Order, error) {
+	// 合并重复的商品，保持请求中的商品顺序
+	quantities := make(map[uint]int)
+	productIDs := make([]uint, 0, len(req.Items))
+	for _, item := range req.Items {
+		if _, exists := quantities[item.ProductID]; !exists {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	var order Order
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 1. 校验用户
+		var user User
+		if err := tx.First(&user, req.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("查询用户失败: %v", err)
+		}
+
+		// 2. 校验收货地址必须属于该用户
+		var address Address
+		if err := tx.First(&address, req.AddressID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAddressNotFound
+			}
+			return fmt.Errorf("查询收货地址失败: %v", err)
+		}
+		if address.UserID != user.ID {
+			return ErrAddressNotOwned
+		}
+
+		// 3. 查询商品并校验状态
+		var products []Product
+		if err := tx.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+			return fmt.Errorf("查询商品失败: %v", err)
+		}
+		productMap := make(map[uint]Product, len(products))
+		for _, product := range products {
+			productMap[product.ID] = product
+		}
+
+		// 4. 快照商品信息并计算金额
+		items := make([]OrderItem, 0, len(productIDs))
+		var totalAmount Money
+		for _, productID := range productIDs {
+			product, ok := productMap[productID]
+			if !ok {
+				return fmt.Errorf("%w: ID %d", ErrProductNotFound, productID)
+			}
+			if product.Status == ProductStatusOffSale {
+				return fmt.Errorf("%w: %s", ErrProductOffSale, product.Name)
+			}
+
+			quantity := quantities[productID]
+			subtotal := product.Price.Mul(quantity)
+			totalAmount = totalAmount.Add(subtotal)
+			items = append(items, OrderItem{
+				ProductID:    product.ID,
+				ProductName:  product.Name,
+				ProductImage: product.Image,
+				Price:        product.Price,
+				Quantity:     quantity,
+				Subtotal:     subtotal,
+			})
+		}
+
+		// 5. 写入订单及订单明细（GORM 会同时创建关联的 OrderItems）
+		order = Order{
+			OrderNo:        generateOrderNo(),
+			UserID:         user.ID,
+			AddressID:      address.ID,
+			TotalAmount:    totalAmount,
+			DiscountAmount: Money{},
+			PayAmount:      totalAmount,
+			Status:         OrderStatusPending,
+			Remark:         req.Remark,
+			OrderItems:     items,
+		}
+		snapshotAddress(&order, address)
+		if err := tx.Create(&order).Error; err != nil {
+			return fmt.Errorf("创建订单失败: %v", err)
+		}
+
+		// 6. 预占库存并写入预占记录和库存流水，支付时才扣减库存、增加销量
+		return reserveOrderStock(tx, &order, req.OperatorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// snapshotAddress 将收货地址快照到订单上
+// 用户之后修改或删除地址不会影响已下单的订单，和订单明细快照商品名称、单价的做法一致
+func snapshotAddress(order *Order, address Address) {
+	order.ReceiverName = address.ReceiverName
+	order.ReceiverPhone = address.ReceiverPhone
+	order.Province = address.Province
+	order.City = address.City
+	order.District = address.District
+	order.AddressDetail = address.Detail
+	order.PostalCode = address.PostalCode
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 订单状态流转相关的业务错误
+var (
+	ErrOrderNotFound     = errors.New("订单不存在")
+	ErrIllegalTransition = errors.New("非法的订单状态流转")
+)
+
+// OrderEvent 订单状态流转事件
+type OrderEvent string
+
+// 订单状态流转事件
+const (
+	OrderEventPay      OrderEvent = "pay"      // 支付
+	OrderEventShip     OrderEvent = "ship"     // 发货
+	OrderEventComplete OrderEvent = "complete" // 确认收货
+	OrderEventCancel   OrderEvent = "cancel"   // 取消
+)
+
+// orderStatusText 订单状态名称
+var orderStatusText = map[int8]string{
+	OrderStatusPending:   "待支付",
+	OrderStatusPaid:      "已支付",
+	OrderStatusShipped:   "已发货",
+	OrderStatusCompleted: "已完成",
+	OrderStatusCancelled: "已取消",
+}
+
+// orderTransition 状态流转规则：允许的源状态 -> 目标状态
+type orderTransition struct {
+	From []int8
+	To   int8
+}
+
+// orderTransitions 订单状态机，定义所有合法的状态流转
+//
+//	待支付 --pay--> 已支付 --ship--> 已发货 --complete--> 已完成
+//	  │               │
+//	  └----cancel-----┴----> 已取消
+var orderTransitions = map[OrderEvent]orderTransition{
+	OrderEventPay:      {From: []int8{OrderStatusPending}, To: OrderStatusPaid},
+	OrderEventShip:     {From: []int8{OrderStatusPaid}, To: OrderStatusShipped},
+	OrderEventComplete: {From: []int8{OrderStatusShipped}, To: OrderStatusCompleted},
+	OrderEventCancel:   {From: []int8{OrderStatusPending, OrderStatusPaid}, To: OrderStatusCancelled},
+}
+
+// nextOrderStatus 根据当前状态和事件计算目标状态，非法流转返回 ErrIllegalTransition
+func nextOrderStatus(current int8, event OrderEvent) (int8, error) {
+	transition, ok := orderTransitions[event]
+	if !ok {
+		return current, fmt.Errorf("%w: 未知事件 %s", ErrIllegalTransition, event)
+	}
+	for _, from := range transition.From {
+		if from == current {
+			return transition.To, nil
+		}
+	}
+	return current, fmt.Errorf("%w: %s 状态的订单不能执行 %s",
+		ErrIllegalTransition, orderStatusText[current], event)
+}
+
+// transitionOrder 在一个事务中执行订单状态流转
+// 支付/发货/完成时记录对应的时间；支付时将预占的库存转为扣减，取消时释放订单占用的库存
+// operatorID 为执行操作的用户，记录在库存流水中，0 表示系统自动执行（超时未支付自动取消）
+func transitionOrder(db *gorm.DB, orderID uint, event OrderEvent, payMethod string, operatorID uint) (*Order, error) {
+	var order Order
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("OrderItems").First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return fmt.Errorf("查询订单失败: %v", err)
+		}
+
+		next, err := nextOrderStatus(order.Status, event)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{"status": next}
+		switch event {
+		case OrderEventPay:
+			updates["pay_time"] = now
+			if payMethod != "" {
+				updates["pay_method"] = payMethod
+			}
+		case OrderEventShip:
+			updates["ship_time"] = now
+		case OrderEventComplete:
+			updates["complete_time"] = now
+		}
+
+		// 带上当前状态作为条件，防止并发请求重复流转
+		result := tx.Model(&Order{}).
+			Where("id = ? AND status = ?", order.ID, order.Status).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("更新订单状态失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: 订单状态已被修改", ErrIllegalTransition)
+		}
+
+		switch event {
+		case OrderEventPay:
+			if err := deductReservedStock(tx, &order, operatorID); err != nil {
+				return err
+			}
+		case OrderEventCancel:
+			if err := releaseOrderStock(tx, &order, operatorID); err != nil {
+				return err
+			}
+		}
+
+		// 重新读取订单，返回最新数据
+		order = Order{ID: order.ID}
+		if err := tx.Preload("OrderItems").First(&order).Error; err != nil {
+			return fmt.Errorf("查询订单失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
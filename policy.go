@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 用户角色
+const (
+	RoleCustomer = "customer" // 顾客：只能访问自己的订单和地址
+	RoleOperator = "operator" // 运营：可以查看所有订单、修改订单状态、维护商品分类
+	RoleAdmin    = "admin"    // 管理员：拥有全部权限，包括用户管理和测试数据
+)
+
+// ErrForbidden 无权访问
+var ErrForbidden = errors.New("无权访问")
+
+// 权限判断函数
+// 只依赖用户和资源本身，不依赖 HTTP 和数据库，便于单独测试；actor 为 nil 表示未登录
+
+// isValidRole 角色是否合法
+func isValidRole(role string) bool {
+	switch role {
+	case RoleCustomer, RoleOperator, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasRole 用户是否拥有指定角色之一
+func hasRole(actor *User, roles ...string) bool {
+	if actor == nil {
+		return false
+	}
+	for _, role := range roles {
+		if actor.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// isStaff 是否为运营或管理员
+func isStaff(actor *User) bool {
+	return hasRole(actor, RoleOperator, RoleAdmin)
+}
+
+// canListUsers 是否可以查看用户列表（包含所有用户的手机号和邮箱）
+func canListUsers(actor *User) bool {
+	return hasRole(actor, RoleAdmin)
+}
+
+// canManageUsers 是否可以修改用户角色
+func canManageUsers(actor *User) bool {
+	return hasRole(actor, RoleAdmin)
+}
+
+// canSeedData 是否可以插入测试数据
+func canSeedData(actor *User) bool {
+	return hasRole(actor, RoleAdmin)
+}
+
+// canManageCatalog 是否可以维护商品分类
+func canManageCatalog(actor *User) bool {
+	return isStaff(actor)
+}
+
+// canAdjustStock 是否可以人工调整商品库存
+func canAdjustStock(actor *User) bool {
+	return hasRole(actor, RoleAdmin)
+}
+
+// canViewSalesData 是否可以查看商品的购买记录和销售统计
+func canViewSalesData(actor *User) bool {
+	return isStaff(actor)
+}
+
+// canListAllOrders 是否可以查看所有用户的订单，否则只能查看自己的订单
+func canListAllOrders(actor *User) bool {
+	return isStaff(actor)
+}
+
+// canAccessUser 是否可以访问指定用户的订单、地址等数据
+func canAccessUser(actor *User, userID uint) bool {
+	if actor == nil {
+		return false
+	}
+	return actor.ID == userID || isStaff(actor)
+}
+
+// canManageAddresses 是否可以新增、修改、删除指定用户的收货地址
+// 运营可以查看地址用于发货，但只有用户本人和管理员可以修改
+func canManageAddresses(actor *User, userID uint) bool {
+	if actor == nil {
+		return false
+	}
+	return actor.ID == userID || hasRole(actor, RoleAdmin)
+}
+
+// canViewOrder 是否可以查看订单
+func canViewOrder(actor *User, order *Order) bool {
+	return order != nil && canAccessUser(actor, order.UserID)
+}
+
+// canPlaceOrder 是否可以为指定用户下单
+// 顾客只能为自己下单，管理员可以代客下单
+func canPlaceOrder(actor *User, userID uint) bool {
+	if actor == nil {
+		return false
+	}
+	return actor.ID == userID || hasRole(actor, RoleAdmin)
+}
+
+// canTransitionOrder 是否可以对订单执行状态流转
+// 运营和管理员可以执行所有流转；下单用户可以支付、确认收货和取消自己的订单，不能发货
+func canTransitionOrder(actor *User, order *Order, event OrderEvent) bool {
+	if actor == nil || order == nil {
+		return false
+	}
+	if isStaff(actor) {
+		return true
+	}
+	if actor.ID != order.UserID {
+		return false
+	}
+	switch event {
+	case OrderEventPay, OrderEventComplete, OrderEventCancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// RequirePolicy 权限校验中间件，当前用户不满足 allowed 时返回 403，需要在 AuthRequired 之后使用
+// 例如: RequirePolicy(canSeedData)
+func RequirePolicy(allowed func(actor *User) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allowed(currentUser(c)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, Response{
+				Code:    403,
+				Message: ErrForbidden.Error(),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// respondForbidden 返回 403 响应
+func respondForbidden(c *gin.Context) {
+	c.JSON(http.StatusForbidden, Response{
+		Code:    403,
+		Message: ErrForbidden.Error(),
+	})
+}
@@ -0,0 +1,145 @@
+package main
+
+import "testing"
+
+// 权限测试使用的用户，customer 和 other 是两个不同的顾客
+var (
+	policyCustomer = &User{ID: 1, Role: RoleCustomer}
+	policyOther    = &User{ID: 2, Role: RoleCustomer}
+	policyOperator = &User{ID: 3, Role: RoleOperator}
+	policyAdmin    = &User{ID: 4, Role: RoleAdmin}
+	policyUnknown  = &User{ID: 5, Role: "root"} // 数据库中被改成未知角色的用户，按普通用户处理
+)
+
+// policyCase 一个用户期望的判断结果
+type policyCase struct {
+	name  string
+	actor *User
+	want  bool
+}
+
+// policyCases 按 未登录、顾客、运营、管理员、未知角色 的顺序生成用例
+func policyCases(anonymous, customer, operator, admin, unknown bool) []policyCase {
+	return []policyCase{
+		{"未登录", nil, anonymous},
+		{"顾客", policyCustomer, customer},
+		{"运营", policyOperator, operator},
+		{"管理员", policyAdmin, admin},
+		{"未知角色", policyUnknown, unknown},
+	}
+}
+
+func TestRolePolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy func(actor *User) bool
+		cases  []policyCase
+	}{
+		{"canListUsers", canListUsers, policyCases(false, false, false, true, false)},
+		{"canManageUsers", canManageUsers, policyCases(false, false, false, true, false)},
+		{"canSeedData", canSeedData, policyCases(false, false, false, true, false)},
+		{"canAdjustStock", canAdjustStock, policyCases(false, false, false, true, false)},
+		{"canManageCatalog", canManageCatalog, policyCases(false, false, true, true, false)},
+		{"canViewSalesData", canViewSalesData, policyCases(false, false, true, true, false)},
+		{"canListAllOrders", canListAllOrders, policyCases(false, false, true, true, false)},
+		{"isStaff", isStaff, policyCases(false, false, true, true, false)},
+	}
+	for _, tt := range tests {
+		for _, tc := range tt.cases {
+			if got := tt.policy(tc.actor); got != tc.want {
+				t.Errorf("%s(%s) = %v，期望 %v", tt.name, tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestUserPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy func(actor *User, userID uint) bool
+		userID uint
+		cases  []policyCase
+	}{
+		// 访问自己的数据
+		{"canAccessUser", canAccessUser, policyCustomer.ID, policyCases(false, true, true, true, false)},
+		{"canManageAddresses", canManageAddresses, policyCustomer.ID, policyCases(false, true, false, true, false)},
+		{"canPlaceOrder", canPlaceOrder, policyCustomer.ID, policyCases(false, true, false, true, false)},
+		// 访问其他用户的数据
+		{"canAccessUser", canAccessUser, policyOther.ID, policyCases(false, false, true, true, false)},
+		{"canManageAddresses", canManageAddresses, policyOther.ID, policyCases(false, false, false, true, false)},
+		{"canPlaceOrder", canPlaceOrder, policyOther.ID, policyCases(false, false, false, true, false)},
+		// 未知角色的用户仍然可以访问自己的数据
+		{"canAccessUser", canAccessUser, policyUnknown.ID, policyCases(false, false, true, true, true)},
+	}
+	for _, tt := range tests {
+		for _, tc := range tt.cases {
+			if got := tt.policy(tc.actor, tt.userID); got != tc.want {
+				t.Errorf("%s(%s, 用户 %d) = %v，期望 %v", tt.name, tc.name, tt.userID, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestCanViewOrder(t *testing.T) {
+	own := &Order{ID: 1, UserID: policyCustomer.ID}
+	others := &Order{ID: 2, UserID: policyOther.ID}
+
+	tests := []struct {
+		name  string
+		order *Order
+		cases []policyCase
+	}{
+		{"自己的订单", own, policyCases(false, true, true, true, false)},
+		{"其他用户的订单", others, policyCases(false, false, true, true, false)},
+		{"订单为 nil", nil, policyCases(false, false, false, false, false)},
+	}
+	for _, tt := range tests {
+		for _, tc := range tt.cases {
+			if got := canViewOrder(tc.actor, tt.order); got != tc.want {
+				t.Errorf("canViewOrder(%s, %s) = %v，期望 %v", tc.name, tt.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestCanTransitionOrder(t *testing.T) {
+	own := &Order{ID: 1, UserID: policyCustomer.ID}
+	others := &Order{ID: 2, UserID: policyOther.ID}
+
+	tests := []struct {
+		order *Order
+		event OrderEvent
+		cases []policyCase
+	}{
+		// 下单用户可以支付、确认收货、取消，不能发货
+		{own, OrderEventPay, policyCases(false, true, true, true, false)},
+		{own, OrderEventComplete, policyCases(false, true, true, true, false)},
+		{own, OrderEventCancel, policyCases(false, true, true, true, false)},
+		{own, OrderEventShip, policyCases(false, false, true, true, false)},
+		// 其他用户的订单只有运营和管理员可以操作
+		{others, OrderEventPay, policyCases(false, false, true, true, false)},
+		{others, OrderEventCancel, policyCases(false, false, true, true, false)},
+		{others, OrderEventShip, policyCases(false, false, true, true, false)},
+		{nil, OrderEventCancel, policyCases(false, false, false, false, false)},
+	}
+	for _, tt := range tests {
+		for _, tc := range tt.cases {
+			if got := canTransitionOrder(tc.actor, tt.order, tt.event); got != tc.want {
+				t.Errorf("canTransitionOrder(%s, %+v, %s) = %v，期望 %v", tc.name, tt.order, tt.event, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestIsValidRole(t *testing.T) {
+	for _, role := range []string{RoleCustomer, RoleOperator, RoleAdmin} {
+		if !isValidRole(role) {
+			t.Errorf("isValidRole(%q) = false", role)
+		}
+	}
+	for _, role := range []string{"", "root", "Admin", " admin"} {
+		if isValidRole(role) {
+			t.Errorf("isValidRole(%q) = true", role)
+		}
+	}
+}
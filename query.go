@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 分页参数默认值
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ErrInvalidListParams 列表查询参数错误
+var ErrInvalidListParams = errors.New("无效的查询参数")
+
+// sortKind 排序字段的值类型，用于从游标中还原排序值
+type sortKind int
+
+const (
+	sortKindInt   sortKind = iota // 整数，例如 id、sort、sales
+	sortKindMoney                 // 金额，例如 price、pay_amount
+	sortKindTime                  // 时间，例如 created_at
+)
+
+// sortField 可排序字段
+type sortField struct {
+	Column string   // 数据库列名
+	Kind   sortKind // 值类型
+}
+
+// listSpec 列表接口的排序规则
+// 只有 SortFields 中列出的字段可以用于排序，防止通过 sort 参数注入任意 SQL
+type listSpec struct {
+	SortFields  map[string]sortField // 对外参数名 -> 排序字段
+	DefaultSort string               // 默认排序字段
+	DefaultDesc bool                 // 默认是否倒序
+}
+
+// ListParams 列表查询参数
+//   - page/page_size: 传统分页
+//   - cursor: 游标分页，传入上一页返回的 next_cursor，优先级高于 page
+//   - sort/order: 排序字段和方向（asc/desc）
+type ListParams struct {
+	Page     int
+	PageSize int
+	Cursor   *listCursor
+	Sort     sortField
+	SortName string
+	Desc     bool
+}
+
+// PageResult 分页结果，放在 Response.Data 中返回
+type PageResult struct {
+	Items      interface{} `json:"items"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page,omitempty"`
+	PageSize   int         `json:"page_size"`
+	HasMore    bool        `json:"has_more"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// listCursor 游标内容：上一页最后一条记录的排序值和 ID
+type listCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// parseListParams 从请求中解析分页和排序参数
+func parseListParams(c *gin.Context, spec listSpec) (ListParams, error) {
+	params := ListParams{
+		Page:     1,
+		PageSize: defaultPageSize,
+		SortName: spec.DefaultSort,
+		Desc:     spec.DefaultDesc,
+	}
+
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return params, fmt.Errorf("%w: page 必须是大于 0 的整数", ErrInvalidListParams)
+		}
+		params.Page = page
+	}
+	if value := c.Query("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return params, fmt.Errorf("%w: page_size 必须在 1-%d 之间", ErrInvalidListParams, maxPageSize)
+		}
+		params.PageSize = pageSize
+	}
+
+	if value := c.Query("sort"); value != "" {
+		if _, ok := spec.SortFields[value]; !ok {
+			return params, fmt.Errorf("%w: 不支持按 %s 排序", ErrInvalidListParams, value)
+		}
+		params.SortName = value
+	}
+	switch strings.ToLower(c.Query("order")) {
+	case "":
+	case "asc":
+		params.Desc = false
+	case "desc":
+		params.Desc = true
+	default:
+		return params, fmt.Errorf("%w: order 只能是 asc 或 desc", ErrInvalidListParams)
+	}
+	params.Sort = spec.SortFields[params.SortName]
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := decodeCursor(value)
+		if err != nil || cursor.Sort != params.SortName {
+			return params, fmt.Errorf("%w: cursor 无效或与排序字段不匹配", ErrInvalidListParams)
+		}
+		params.Cursor = cursor
+	}
+
+	return params, nil
+}
+
+// paginate 执行分页查询
+// query 应当已经带上过滤条件；paginate 负责统计总数、排序、游标条件和分页
+// preloads 只作用于当前页的数据，不影响总数统计
+func paginate[T any](query *gorm.DB, params ListParams, preloads ...string) (PageResult, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return PageResult{}, fmt.Errorf("统计总数失败: %v", err)
+	}
+
+	direction, compare := "ASC", ">"
+	if params.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	page := query.Session(&gorm.Session{})
+	if params.Cursor != nil {
+		value, err := cursorValue(params.Cursor, params.Sort.Kind)
+		if err != nil {
+			return PageResult{}, fmt.Errorf("%w: cursor 无效", ErrInvalidListParams)
+		}
+		// 以 (排序字段, id) 组合作为游标，保证排序值相同时分页结果也稳定
+		if params.Sort.Column == "id" {
+			page = page.Where(fmt.Sprintf("id %s ?", compare), params.Cursor.ID)
+		} else {
+			page = page.Where(fmt.Sprintf("((%s %s ?) OR (%s = ? AND id %s ?))",
+				params.Sort.Column, compare, params.Sort.Column, compare),
+				value, value, params.Cursor.ID)
+		}
+	} else {
+		page = page.Offset((params.Page - 1) * params.PageSize)
+	}
+
+	order := params.Sort.Column + " " + direction
+	if params.Sort.Column != "id" {
+		order += ", id " + direction
+	}
+
+	for _, preload := range preloads {
+		page = page.Preload(preload)
+	}
+
+	// 多查询一条，用于判断是否还有下一页
+	var items []T
+	if err := page.Order(order).Limit(params.PageSize + 1).Find(&items).Error; err != nil {
+		return PageResult{}, fmt.Errorf("查询数据失败: %v", err)
+	}
+
+	result := PageResult{
+		Total:    total,
+		PageSize: params.PageSize,
+	}
+	if params.Cursor == nil {
+		result.Page = params.Page
+	}
+	if len(items) > params.PageSize {
+		items = items[:params.PageSize]
+		result.HasMore = true
+
+		cursor, err := encodeCursor(query, params, &items[len(items)-1])
+		if err != nil {
+			return PageResult{}, err
+		}
+		result.NextCursor = cursor
+	}
+	if items == nil {
+		items = []T{}
+	}
+	result.Items = items
+
+	return result, nil
+}
+
+// encodeCursor 根据一页中最后一条记录生成下一页的游标
+func encodeCursor(db *gorm.DB, params ListParams, last interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(last); err != nil {
+		return "", fmt.Errorf("生成游标失败: %v", err)
+	}
+	sortField := stmt.Schema.LookUpField(params.Sort.Column)
+	idField := stmt.Schema.LookUpField("id")
+	if sortField == nil || idField == nil {
+		return "", fmt.Errorf("生成游标失败: 未知字段 %s", params.Sort.Column)
+	}
+
+	row := reflect.ValueOf(last).Elem()
+	sortValue, _ := sortField.ValueOf(context.Background(), row)
+	idValue, _ := idField.ValueOf(context.Background(), row)
+
+	rawValue, err := json.Marshal(sortValue)
+	if err != nil {
+		return "", fmt.Errorf("生成游标失败: %v", err)
+	}
+	id, _ := idValue.(uint)
+	data, err := json.Marshal(listCursor{Sort: params.SortName, Value: rawValue, ID: id})
+	if err != nil {
+		return "", fmt.Errorf("生成游标失败: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解析游标字符串
+func decodeCursor(value string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// cursorValue 将游标中的排序值还原为对应的 Go 类型，作为 SQL 参数使用
+func cursorValue(cursor *listCursor, kind sortKind) (interface{}, error) {
+	switch kind {
+	case sortKindMoney:
+		var m Money
+		err := json.Unmarshal(cursor.Value, &m)
+		return m, err
+	case sortKindTime:
+		var t time.Time
+		err := json.Unmarshal(cursor.Value, &t)
+		return t, err
+	default:
+		var n int64
+		err := json.Unmarshal(cursor.Value, &n)
+		return n, err
+	}
+}
+
+// parseTimeParam 解析时间查询参数，支持 2006-01-02 和 RFC3339 两种格式
+// endOfDay 为 true 且只传了日期时，返回次日零点，用于 "< 结束时间" 的左闭右开区间
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// TimeRange 时间区间 [From, To)，为 nil 的一端不限制
+type TimeRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// MoneyRange 金额区间 [Min, Max]，为 nil 的一端不限制
+type MoneyRange struct {
+	Min *Money
+	Max *Money
+}
+
+// parseTimeRange 解析 start_date/end_date 参数
+func parseTimeRange(c *gin.Context) (TimeRange, error) {
+	var r TimeRange
+	if value := c.Query("start_date"); value != "" {
+		start, err := parseTimeParam(value, false)
+		if err != nil {
+			return r, fmt.Errorf("%w: start_date 格式应为 2006-01-02 或 RFC3339", ErrInvalidListParams)
+		}
+		r.From = &start
+	}
+	if value := c.Query("end_date"); value != "" {
+		end, err := parseTimeParam(value, true)
+		if err != nil {
+			return r, fmt.Errorf("%w: end_date 格式应为 2006-01-02 或 RFC3339", ErrInvalidListParams)
+		}
+		r.To = &end
+	}
+	return r, nil
+}
+
+// parseUintParam 解析无符号整数参数，例如 user_id、category_id；未传时返回 nil
+func parseUintParam(c *gin.Context, param string) (*uint, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s 必须是正整数", ErrInvalidListParams, param)
+	}
+	id := uint(n)
+	return &id, nil
+}
+
+// parseStatusParam 解析状态参数，支持逗号分隔的多个状态，例如 status=0,1
+func parseStatusParam(c *gin.Context) ([]int8, error) {
+	value := c.Query("status")
+	if value == "" {
+		return nil, nil
+	}
+	var statuses []int8
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.ParseInt(strings.TrimSpace(part), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%w: status 必须是整数", ErrInvalidListParams)
+		}
+		statuses = append(statuses, int8(n))
+	}
+	return statuses, nil
+}
+
+// parseMoneyRange 解析金额区间参数，例如 min_price/max_price
+func parseMoneyRange(c *gin.Context, minParam, maxParam string) (MoneyRange, error) {
+	var r MoneyRange
+	if value := c.Query(minParam); value != "" {
+		amount, err := ParseMoney(value)
+		if err != nil {
+			return r, fmt.Errorf("%w: %s 必须是金额", ErrInvalidListParams, minParam)
+		}
+		r.Min = &amount
+	}
+	if value := c.Query(maxParam); value != "" {
+		amount, err := ParseMoney(value)
+		if err != nil {
+			return r, fmt.Errorf("%w: %s 必须是金额", ErrInvalidListParams, maxParam)
+		}
+		r.Max = &amount
+	}
+	return r, nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 报表接口的公共参数：
+//   start_date/end_date  下单时间范围，格式同订单列表
+//   format               json（默认）或 csv，csv 时以附件形式下载
+
+// 排行榜数量
+const (
+	defaultReportLimit = 10
+	maxReportLimit     = 100
+)
+
+// reportFormatCSV 导出 CSV 的 format 参数
+const reportFormatCSV = "csv"
+
+// utf8BOM 写在 CSV 开头，Excel 打开时才能正确识别中文
+const utf8BOM = "\ufeff"
+
+// csvFormulaPrefixes 以这些字符开头的单元格会被电子表格当作公式执行
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvSafeCell 在可能被当作公式的单元格前加上 '，商品名、用户名、地址等用户输入的内容原样写入 CSV 时，
+// 打开文件就会执行其中的公式；负数等合法的数字保持原样
+func csvSafeCell(value string) string {
+	if value == "" || !strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// checkReportFormat 校验 format 参数
+func checkReportFormat(c *gin.Context) error {
+	switch c.Query("format") {
+	case "", "json", reportFormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("%w: format 必须是 json 或 csv", ErrInvalidListParams)
+	}
+}
+
+// parseReportLimit 解析排行榜的 limit 参数，默认 10，最大 100
+func parseReportLimit(c *gin.Context) (int, error) {
+	value := c.Query("limit")
+	if value == "" {
+		return defaultReportLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxReportLimit {
+		return 0, fmt.Errorf("%w: limit 必须是 1 到 %d 之间的整数", ErrInvalidListParams, maxReportLimit)
+	}
+	return limit, nil
+}
+
+// respondReport 按 format 参数返回 JSON 或 CSV
+// records 为 CSV 的所有行，第一行是表头，列名与 JSON 字段名一致
+func respondReport(c *gin.Context, name string, data interface{}, records [][]string) {
+	if c.Query("format") != reportFormatCSV {
+		c.JSON(http.StatusOK, Response{
+			Code:    200,
+			Message: "查询成功",
+			Data:    data,
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+	// 响应头已经发出，写入失败（通常是客户端断开）时无法再返回错误响应，只记录日志
+	if _, err := c.Writer.WriteString(utf8BOM); err != nil {
+		slog.ErrorContext(c.Request.Context(), "导出 CSV 失败", "report", name, "error", err)
+		return
+	}
+	w := csv.NewWriter(c.Writer)
+	for _, record := range records {
+		for i, value := range record {
+			record[i] = csvSafeCell(value)
+		}
+		if err := w.Write(record); err != nil {
+			break
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "导出 CSV 失败", "report", name, "error", err)
+	}
+}
+
+// reportRequest 解析报表的公共参数，参数错误时返回 400 并返回 false
+func reportRequest(c *gin.Context) (TimeRange, bool) {
+	created, err := parseTimeRange(c)
+	if err == nil {
+		err = checkReportFormat(c)
+	}
+	if err != nil {
+		respondListError(c, err)
+		return created, false
+	}
+	return created, true
+}
+
+// GetReportOverview 销售概览：下单数、支付转化率、销售额、平均每单金额
+// GET /reports/overview?start_date=2026-10-01&end_date=2026-10-31
+func (s *Server) GetReportOverview(c *gin.Context) {
+	created, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+
+	overview, err := s.reports.Overview(c.Request.Context(), created)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	respondReport(c, "overview", overview, [][]string{
+		{"order_count", "paid_order_count", "conversion_rate", "sales_order_count", "refunded_order_count", "gmv", "average_order_value"},
+		{
+			strconv.Itoa(overview.OrderCount),
+			strconv.Itoa(overview.PaidOrderCount),
+			strconv.FormatFloat(overview.ConversionRate, 'f', 4, 64),
+			strconv.Itoa(overview.SalesOrderCount),
+			strconv.Itoa(overview.RefundedOrderCount),
+			overview.GMV.String(),
+			overview.AverageOrderValue.String(),
+		},
+	})
+}
+
+// GetSalesTrend 按天、周、月统计下单数和销售额
+// GET /reports/sales?interval=day&start_date=2026-10-01&end_date=2026-10-31
+func (s *Server) GetSalesTrend(c *gin.Context) {
+	created, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+	interval := c.DefaultQuery("interval", SalesIntervalDay)
+	switch interval {
+	case SalesIntervalDay, SalesIntervalWeek, SalesIntervalMonth:
+	default:
+		respondListError(c, fmt.Errorf("%w: interval 必须是 day、week 或 month", ErrInvalidListParams))
+		return
+	}
+
+	rows, err := s.reports.SalesTrend(c.Request.Context(), created, interval)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	records := [][]string{{"period", "order_count", "sales_order_count", "gmv"}}
+	for _, row := range rows {
+		records = append(records, []string{
+			row.Period, strconv.Itoa(row.OrderCount), strconv.Itoa(row.SalesOrderCount), row.GMV.String(),
+		})
+	}
+	respondReport(c, "sales-by-"+interval, rows, records)
+}
+
+// GetTopProducts 商品排行，按销售额（by=revenue，默认）或销量（by=quantity）排序
+// GET /reports/top-products?by=quantity&limit=10
+func (s *Server) GetTopProducts(c *gin.Context) {
+	created, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+	by := c.DefaultQuery("by", RankByRevenue)
+	if by != RankByRevenue && by != RankByQuantity {
+		respondListError(c, fmt.Errorf("%w: by 必须是 revenue 或 quantity", ErrInvalidListParams))
+		return
+	}
+	limit, err := parseReportLimit(c)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	rows, err := s.reports.TopProducts(c.Request.Context(), created, by, limit)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	records := [][]string{{"product_id", "product_name", "quantity", "revenue", "order_count"}}
+	for _, row := range rows {
+		records = append(records, []string{
+			strconv.FormatUint(uint64(row.ProductID), 10), row.ProductName,
+			strconv.Itoa(row.Quantity), row.Revenue.String(), strconv.Itoa(row.OrderCount),
+		})
+	}
+	respondReport(c, "top-products-by-"+by, rows, records)
+}
+
+// GetTopCustomers 客户排行，按消费金额排序
+// GET /reports/top-customers?limit=10
+func (s *Server) GetTopCustomers(c *gin.Context) {
+	created, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+	limit, err := parseReportLimit(c)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	rows, err := s.reports.TopCustomers(c.Request.Context(), created, limit)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	records := [][]string{{"user_id", "username", "order_count", "total_amount", "average_order_value"}}
+	for _, row := range rows {
+		records = append(records, []string{
+			strconv.FormatUint(uint64(row.UserID), 10), row.Username,
+			strconv.Itoa(row.OrderCount), row.TotalAmount.String(), row.AverageOrderValue.String(),
+		})
+	}
+	respondReport(c, "top-customers", rows, records)
+}
+
+// GetSalesByRegion 按省份（level=province，默认）或城市（level=city）统计销售额
+// GET /reports/regions?level=city
+func (s *Server) GetSalesByRegion(c *gin.Context) {
+	created, ok := reportRequest(c)
+	if !ok {
+		return
+	}
+	level := c.DefaultQuery("level", RegionLevelProvince)
+	if level != RegionLevelProvince && level != RegionLevelCity {
+		respondListError(c, fmt.Errorf("%w: level 必须是 province 或 city", ErrInvalidListParams))
+		return
+	}
+
+	rows, err := s.reports.SalesByRegion(c.Request.Context(), created, level)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	header := []string{"province", "order_count", "gmv"}
+	if level == RegionLevelCity {
+		header = []string{"province", "city", "order_count", "gmv"}
+	}
+	records := [][]string{header}
+	for _, row := range rows {
+		record := []string{row.Province}
+		if level == RegionLevelCity {
+			record = append(record, row.City)
+		}
+		records = append(records, append(record, strconv.Itoa(row.OrderCount), row.GMV.String()))
+	}
+	respondReport(c, "sales-by-"+level, rows, records)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newReportTestServer 在测试数据的基础上再创建两个订单：
+// 张三购买手机保护壳 × 3 并支付；张三购买 iPhone × 1，支付后取消（退款）
+//
+// 计入销售额的订单：已支付的 iPhone 7999（张三，北京）、已发货的 MacBook 14999（李四，上海）、手机保护壳 297（张三，北京）
+func newReportTestServer(t *testing.T) *testServer {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+	place := func(productID uint, quantity int, events ...OrderEvent) {
+		req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: productID, Quantity: quantity}}}
+		var order Order
+		ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated).decode(t, &order)
+		for _, event := range events {
+			ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/%s", order.ID, event), zhangsan, nil, http.StatusOK)
+		}
+	}
+	place(fixtureCaseID, 3, OrderEventPay)
+	place(fixtureIPhoneID, 1, OrderEventPay, OrderEventCancel)
+	return ts
+}
+
+func TestReports(t *testing.T) {
+	ts := newReportTestServer(t)
+	admin := ts.login("admin")
+
+	var overview ReportOverview
+	ts.expect(http.MethodGet, "/reports/overview", admin, nil, http.StatusOK).decode(t, &overview)
+	wantOverview := ReportOverview{
+		OrderCount: 5, PaidOrderCount: 4, ConversionRate: 0.8, SalesOrderCount: 3, RefundedOrderCount: 1,
+		GMV: Yuan(23295), AverageOrderValue: Yuan(7765),
+	}
+	if overview != wantOverview {
+		t.Errorf("销售概览 = %+v，期望 %+v", overview, wantOverview)
+	}
+
+	var trend []SalesTrendRow
+	ts.expect(http.MethodGet, "/reports/sales", admin, nil, http.StatusOK).decode(t, &trend)
+	if len(trend) != 1 || trend[0].OrderCount != 5 || trend[0].SalesOrderCount != 3 || trend[0].GMV != Yuan(23295) {
+		t.Errorf("按天统计 = %+v", trend)
+	}
+
+	var products []TopProductRow
+	ts.expect(http.MethodGet, "/reports/top-products", admin, nil, http.StatusOK).decode(t, &products)
+	wantProducts := []TopProductRow{
+		{ProductID: fixtureMacBookID, ProductName: "MacBook Pro 14英寸", Quantity: 1, Revenue: Yuan(14999), OrderCount: 1},
+		{ProductID: fixtureIPhoneID, ProductName: "iPhone 15 Pro", Quantity: 1, Revenue: Yuan(7999), OrderCount: 1},
+		{ProductID: fixtureCaseID, ProductName: "手机保护壳", Quantity: 3, Revenue: Yuan(297), OrderCount: 1},
+	}
+	if !reflect.DeepEqual(products, wantProducts) {
+		t.Errorf("按销售额的商品排行 = %+v", products)
+	}
+	ts.expect(http.MethodGet, "/reports/top-products?by=quantity&limit=1", admin, nil, http.StatusOK).decode(t, &products)
+	if len(products) != 1 || products[0].ProductID != fixtureCaseID {
+		t.Errorf("按销量的商品排行 = %+v", products)
+	}
+
+	var customers []TopCustomerRow
+	ts.expect(http.MethodGet, "/reports/top-customers", admin, nil, http.StatusOK).decode(t, &customers)
+	wantCustomers := []TopCustomerRow{
+		{UserID: fixtureLisiID, Username: "lisi", OrderCount: 1, TotalAmount: Yuan(14999), AverageOrderValue: Yuan(14999)},
+		{UserID: fixtureZhangsanID, Username: "zhangsan", OrderCount: 2, TotalAmount: Yuan(8296), AverageOrderValue: Yuan(4148)},
+	}
+	if !reflect.DeepEqual(customers, wantCustomers) {
+		t.Errorf("客户排行 = %+v", customers)
+	}
+
+	var regions []RegionSalesRow
+	ts.expect(http.MethodGet, "/reports/regions", admin, nil, http.StatusOK).decode(t, &regions)
+	wantRegions := []RegionSalesRow{
+		{Province: "上海市", OrderCount: 1, GMV: Yuan(14999)},
+		{Province: "北京市", OrderCount: 2, GMV: Yuan(8296)},
+	}
+	if !reflect.DeepEqual(regions, wantRegions) {
+		t.Errorf("按省份统计 = %+v", regions)
+	}
+	ts.expect(http.MethodGet, "/reports/regions?level=city", admin, nil, http.StatusOK).decode(t, &regions)
+	if len(regions) != 2 || regions[0].City != "上海市" || regions[1].City != "北京市" {
+		t.Errorf("按城市统计 = %+v", regions)
+	}
+
+	// 时间范围内没有订单
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	ts.expect(http.MethodGet, "/reports/overview?start_date="+tomorrow, admin, nil, http.StatusOK).decode(t, &overview)
+	if overview != (ReportOverview{}) {
+		t.Errorf("明天之后的销售概览 = %+v", overview)
+	}
+	resp := ts.expect(http.MethodGet, "/reports/top-customers?start_date="+tomorrow, admin, nil, http.StatusOK)
+	if string(resp.Data) != "[]" {
+		t.Errorf("没有数据时应返回空数组: %s", resp.Data)
+	}
+
+	for _, path := range []string{
+		"/reports/sales?interval=year",
+		"/reports/top-products?by=price",
+		"/reports/top-products?limit=0",
+		"/reports/top-customers?limit=101",
+		"/reports/regions?level=district",
+		"/reports/overview?format=xml",
+		"/reports/overview?start_date=yesterday",
+	} {
+		ts.expect(http.MethodGet, path, admin, nil, http.StatusBadRequest)
+	}
+	ts.expect(http.MethodGet, "/reports/overview", ts.login("zhangsan"), nil, http.StatusForbidden)
+	ts.expect(http.MethodGet, "/reports/overview", ts.login("operator"), nil, http.StatusOK)
+}
+
+func TestReportsCSV(t *testing.T) {
+	ts := newReportTestServer(t)
+	admin := ts.login("admin")
+
+	get := func(path string) (*httptest.ResponseRecorder, [][]string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+admin)
+		w := httptest.NewRecorder()
+		ts.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: 状态码 = %d", path, w.Code)
+		}
+		body, ok := strings.CutPrefix(w.Body.String(), utf8BOM)
+		if !ok {
+			t.Errorf("%s: CSV 缺少 BOM", path)
+		}
+		records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: 不是合法的 CSV: %v", path, err)
+		}
+		return w, records
+	}
+
+	w, records := get("/reports/top-customers?format=csv")
+	if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" ||
+		w.Header().Get("Content-Disposition") != `attachment; filename="top-customers.csv"` {
+		t.Errorf("响应头 = %v", w.Header())
+	}
+	want := [][]string{
+		{"user_id", "username", "order_count", "total_amount", "average_order_value"},
+		{"2", "lisi", "1", "14999.00", "14999.00"},
+		{"1", "zhangsan", "2", "8296.00", "4148.00"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("客户排行 CSV = %v", records)
+	}
+
+	_, records = get("/reports/overview?format=csv")
+	if len(records) != 2 || records[1][2] != "0.8000" || records[1][5] != "23295.00" {
+		t.Errorf("销售概览 CSV = %v", records)
+	}
+	_, records = get("/reports/regions?level=city&format=csv")
+	if len(records) != 3 || !reflect.DeepEqual(records[2], []string{"北京市", "北京市", "2", "8296.00"}) {
+		t.Errorf("按城市统计 CSV = %v", records)
+	}
+
+	// 用户输入的内容不会被电子表格当作公式执行
+	ts.db.Model(&Product{}).Where("id = ?", fixtureCaseID).Update("name", `=HYPERLINK("http://evil.example","点击")`)
+	_, records = get("/reports/top-products?format=csv")
+	if len(records) != 4 || records[3][1] != `'=HYPERLINK("http://evil.example","点击")` {
+		t.Errorf("商品排行 CSV = %v", records)
+	}
+}
+
+func TestCSVSafeCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"iPhone 15 Pro", "iPhone 15 Pro"},
+		{"7999.00", "7999.00"},
+		{"-0.01", "-0.01"},
+		{"+86", "+86"},
+		{"=1+1", "'=1+1"},
+		{"+1+cmd|' /C calc'!A0", "'+1+cmd|' /C calc'!A0"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+	for _, tt := range tests {
+		if got := csvSafeCell(tt.value); got != tt.want {
+			t.Errorf("csvSafeCell(%q) = %q，期望 %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// failingWriter 写入总是失败的 ResponseWriter，模拟下载过程中客户端断开
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func (w failingWriter) WriteString(string) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestRespondReportWriteError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureDefaultLogger(t)
+
+	c, _ := gin.CreateTestContext(failingWriter{httptest.NewRecorder()})
+	c.Request = httptest.NewRequest(http.MethodGet, "/reports/overview?format=csv", nil)
+	respondReport(c, "overview", nil, [][]string{{"gmv"}, {"0.00"}})
+
+	lines := logLines(t, buf)
+	if len(lines) != 1 || lines[0]["msg"] != "导出 CSV 失败" || lines[0]["report"] != "overview" ||
+		!strings.Contains(fmt.Sprint(lines[0]["error"]), "connection reset") {
+		t.Errorf("日志 = %v", lines)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// 报表中的销售额只统计已支付、已发货、已完成的订单（salesOrderStatuses），按下单时间过滤
+// 支付后取消的订单算作已转化，但不计入销售额
+
+// 排行榜排序方式
+const (
+	RankByRevenue  = "revenue"
+	RankByQuantity = "quantity"
+)
+
+// 地区统计粒度
+const (
+	RegionLevelProvince = "province"
+	RegionLevelCity     = "city"
+)
+
+// ReportOverview 销售概览
+type ReportOverview struct {
+	OrderCount         int     `json:"order_count"`          // 下单数（所有状态）
+	PaidOrderCount     int     `json:"paid_order_count"`     // 支付过的订单数（含支付后取消的）
+	ConversionRate     float64 `json:"conversion_rate"`      // 支付转化率 = paid_order_count / order_count
+	SalesOrderCount    int     `json:"sales_order_count"`    // 计入销售额的订单数
+	RefundedOrderCount int     `json:"refunded_order_count"` // 支付后取消的订单数
+	GMV                Money   `json:"gmv"`                  // 销售额（实付金额之和）
+	AverageOrderValue  Money   `json:"average_order_value"`  // 平均每单金额 = gmv / sales_order_count
+}
+
+// SalesTrendRow 按时间分桶的销售额和订单数，Period 为分桶的开始日期
+type SalesTrendRow struct {
+	Period          string `json:"period"`
+	OrderCount      int    `json:"order_count"`
+	SalesOrderCount int    `json:"sales_order_count"`
+	GMV             Money  `json:"gmv"`
+}
+
+// TopProductRow 商品销售排行
+type TopProductRow struct {
+	ProductID   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	Revenue     Money  `json:"revenue"` // 订单明细小计之和，不扣除订单优惠
+	OrderCount  int    `json:"order_count"`
+}
+
+// TopCustomerRow 客户消费排行
+type TopCustomerRow struct {
+	UserID            uint   `json:"user_id"`
+	Username          string `json:"username"`
+	OrderCount        int    `json:"order_count"`
+	TotalAmount       Money  `json:"total_amount"`
+	AverageOrderValue Money  `json:"average_order_value"`
+}
+
+// RegionSalesRow 地区销售额，地区取自订单的收货地址快照
+type RegionSalesRow struct {
+	Province   string `json:"province"`
+	City       string `json:"city,omitempty"`
+	OrderCount int    `json:"order_count"`
+	GMV        Money  `json:"gmv"`
+}
+
+// reportOrders 按下单时间过滤的订单查询
+func reportOrders(db *gorm.DB, created TimeRange) *gorm.DB {
+	return applyTimeRange(db.Table("orders").Where("orders.deleted_at IS NULL"), "orders.created_at", created)
+}
+
+// queryReportOverview 统计下单数、支付转化率、销售额和平均每单金额
+func queryReportOverview(db *gorm.DB, created TimeRange) (*ReportOverview, error) {
+	var overview ReportOverview
+	err := reportOrders(db, created).
+		Select(`COUNT(*) AS order_count,
+			COUNT(orders.pay_time) AS paid_order_count,
+			COUNT(CASE WHEN orders.status IN @sold THEN 1 END) AS sales_order_count,
+			COUNT(CASE WHEN orders.status = @cancelled AND orders.pay_time IS NOT NULL THEN 1 END) AS refunded_order_count,
+			COALESCE(SUM(CASE WHEN orders.status IN @sold THEN orders.pay_amount ELSE 0 END), 0) AS gmv`,
+			map[string]interface{}{"sold": salesOrderStatuses, "cancelled": OrderStatusCancelled}).
+		Scan(&overview).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计销售概览失败: %v", err)
+	}
+	if overview.OrderCount > 0 {
+		overview.ConversionRate = math.Round(float64(overview.PaidOrderCount)/float64(overview.OrderCount)*10000) / 10000
+	}
+	overview.AverageOrderValue = overview.GMV.Div(overview.SalesOrderCount)
+	return &overview, nil
+}
+
+// querySalesTrend 按天、周、月统计下单数和销售额，没有订单的分桶不返回
+func querySalesTrend(db *gorm.DB, created TimeRange, interval string) ([]SalesTrendRow, error) {
+	period, err := salesPeriodExpr(db.Dialector.Name(), interval)
+	if err != nil {
+		return nil, err
+	}
+	rows := []SalesTrendRow{}
+	err = reportOrders(db, created).
+		Select(period+` AS period,
+			COUNT(*) AS order_count,
+			COUNT(CASE WHEN orders.status IN @sold THEN 1 END) AS sales_order_count,
+			COALESCE(SUM(CASE WHEN orders.status IN @sold THEN orders.pay_amount ELSE 0 END), 0) AS gmv`,
+			map[string]interface{}{"sold": salesOrderStatuses}).
+		Group("period").Order("period").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计销售趋势失败: %v", err)
+	}
+	return rows, nil
+}
+
+// queryTopProducts 按销售额或销量返回前 limit 个商品
+// 商品名称取当前的商品信息，已删除的商品也会统计
+func queryTopProducts(db *gorm.DB, created TimeRange, by string, limit int) ([]TopProductRow, error) {
+	order := "revenue DESC, quantity DESC, product_id ASC"
+	if by == RankByQuantity {
+		order = "quantity DESC, revenue DESC, product_id ASC"
+	}
+	rows := []TopProductRow{}
+	err := reportOrders(db, created).
+		Joins("JOIN order_items ON order_items.order_id = orders.id AND order_items.deleted_at IS NULL").
+		Joins("JOIN products ON products.id = order_items.product_id").
+		Where("orders.status IN ?", salesOrderStatuses).
+		Select(`products.id AS product_id, products.name AS product_name,
+			SUM(order_items.quantity) AS quantity,
+			SUM(order_items.subtotal) AS revenue,
+			COUNT(DISTINCT orders.id) AS order_count`).
+		Group("products.id, products.name").
+		Order(order).Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计商品排行失败: %v", err)
+	}
+	return rows, nil
+}
+
+// queryTopCustomers 按消费金额返回前 limit 个客户
+func queryTopCustomers(db *gorm.DB, created TimeRange, limit int) ([]TopCustomerRow, error) {
+	rows := []TopCustomerRow{}
+	err := reportOrders(db, created).
+		Joins("JOIN users ON users.id = orders.user_id").
+		Where("orders.status IN ?", salesOrderStatuses).
+		Select(`users.id AS user_id, users.username AS username,
+			COUNT(*) AS order_count,
+			SUM(orders.pay_amount) AS total_amount`).
+		Group("users.id, users.username").
+		Order("total_amount DESC, order_count DESC, user_id ASC").Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计客户排行失败: %v", err)
+	}
+	for i := range rows {
+		rows[i].AverageOrderValue = rows[i].TotalAmount.Div(rows[i].OrderCount)
+	}
+	return rows, nil
+}
+
+// querySalesByRegion 按省份或城市统计销售额，按销售额降序
+func querySalesByRegion(db *gorm.DB, created TimeRange, level string) ([]RegionSalesRow, error) {
+	columns := "orders.province AS province"
+	group := "orders.province"
+	if level == RegionLevelCity {
+		columns += ", orders.city AS city"
+		group += ", orders.city"
+	}
+	rows := []RegionSalesRow{}
+	err := reportOrders(db, created).
+		Where("orders.status IN ?", salesOrderStatuses).
+		Select(columns + ", COUNT(*) AS order_count, SUM(orders.pay_amount) AS gmv").
+		Group(group).
+		Order("gmv DESC, " + group).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("统计地区销售额失败: %v", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import "context"
+
+// 数据访问接口
+// handler 只依赖这些接口，不直接访问数据库；GORM 实现见 repository_gorm.go
+// 查询不到数据时返回对应的业务错误（例如 ErrUserNotFound），handler 据此返回 404
+
+// UserFilter 用户列表过滤条件
+type UserFilter struct {
+	Statuses []int8
+	Created  TimeRange
+}
+
+// ProductFilter 商品列表过滤条件
+type ProductFilter struct {
+	Statuses    []int8
+	CategoryID  *uint  // category_id 参数，只匹配该分类自身
+	CategoryIDs []uint // 分类及其子孙分类，查询分类商品时使用
+	Price       MoneyRange
+	Created     TimeRange
+}
+
+// OrderFilter 订单列表过滤条件
+type OrderFilter struct {
+	Statuses []int8
+	UserID   *uint
+	Created  TimeRange
+}
+
+// StockMovementFilter 库存流水过滤条件
+type StockMovementFilter struct {
+	Types   []string
+	OrderID *uint
+	Created TimeRange
+}
+
+// 销售统计的分桶粒度
+const (
+	SalesIntervalDay   = "day"
+	SalesIntervalWeek  = "week" // 按周一开始的自然周
+	SalesIntervalMonth = "month"
+)
+
+// salesOrderStatuses 默认计入销量的订单状态：已支付、已发货、已完成
+var salesOrderStatuses = []int8{OrderStatusPaid, OrderStatusShipped, OrderStatusCompleted}
+
+// SalesStatsFilter 销售统计条件
+type SalesStatsFilter struct {
+	Statuses []int8    // 计入销量的订单状态，为空时使用 salesOrderStatuses
+	Created  TimeRange // 下单时间
+	Interval string    // 分桶粒度 day、week、month，为空时不分桶
+}
+
+// SalesFigures 一段时间内的销售数据
+// 已取消的订单不计入销量：支付后取消的计入 refunded_*，未支付取消的计入 cancelled_quantity
+type SalesFigures struct {
+	TotalQuantity     int   `json:"total_quantity"`
+	TotalAmount       Money `json:"total_amount"`
+	OrderCount        int   `json:"order_count"`
+	AverageAmount     Money `json:"average_amount"`
+	RefundedQuantity  int   `json:"refunded_quantity"`
+	RefundedAmount    Money `json:"refunded_amount"`
+	CancelledQuantity int   `json:"cancelled_quantity"`
+}
+
+// SalesBucket 一个分桶的销售数据，Period 为分桶的开始日期，例如 2026-10-01
+type SalesBucket struct {
+	Period string `json:"period"`
+	SalesFigures
+}
+
+// ProductSalesStats 商品销售统计
+type ProductSalesStats struct {
+	Product  *Product `json:"product"`
+	Statuses []int8   `json:"statuses"` // 计入销量的订单状态
+	SalesFigures
+	Interval string        `json:"interval,omitempty"`
+	Buckets  []SalesBucket `json:"buckets,omitempty"` // 按 Period 升序，没有订单的分桶不输出
+}
+
+// UserRepository 用户数据访问
+type UserRepository interface {
+	List(ctx context.Context, filter UserFilter, params ListParams) (PageResult, error)
+	FindByID(ctx context.Context, id uint) (*User, error)
+	// FindByAccount 按用户名、手机号或邮箱查询用户
+	FindByAccount(ctx context.Context, account string) (*User, error)
+	// FindWithOrders 查询用户及其订单、订单明细和收货地址
+	FindWithOrders(ctx context.Context, id uint) (*User, error)
+	// FindWithOrderProducts 查询用户及其订单、订单明细和明细对应的商品
+	FindWithOrderProducts(ctx context.Context, id uint) (*User, error)
+	// Create 创建用户，用户名、手机号或邮箱已存在时返回 ErrUsernameTaken 等错误
+	Create(ctx context.Context, user *User) error
+	UpdateRole(ctx context.Context, id uint, role string) (*User, error)
+}
+
+// ProductRepository 商品数据访问
+type ProductRepository interface {
+	List(ctx context.Context, filter ProductFilter, params ListParams) (PageResult, error)
+	FindByID(ctx context.Context, id uint) (*Product, error)
+	// FindWithOrders 查询商品及购买该商品的订单明细、订单和下单用户
+	FindWithOrders(ctx context.Context, id uint) (*Product, error)
+	// SalesStats 按订单状态和下单时间统计商品销量，可以按天、周、月分桶
+	SalesStats(ctx context.Context, id uint, filter SalesStatsFilter) (*ProductSalesStats, error)
+	// StockHistory 分页查询商品的库存流水，商品不存在时返回 ErrProductNotFound
+	StockHistory(ctx context.Context, id uint, filter StockMovementFilter, params ListParams) (PageResult, error)
+	// AdjustStock 人工调整库存，更新库存和写入流水在一个事务中完成
+	AdjustStock(ctx context.Context, id uint, req StockAdjustmentRequest, operatorID uint) (*StockMovement, error)
+}
+
+// OrderRepository 订单数据访问
+type OrderRepository interface {
+	List(ctx context.Context, filter OrderFilter, params ListParams) (PageResult, error)
+	// FindByID 只查询订单本身，不加载关联数据
+	FindByID(ctx context.Context, id uint) (*Order, error)
+	// FindDetail 查询订单及下单用户、订单明细和明细对应的商品
+	FindDetail(ctx context.Context, id uint) (*Order, error)
+	// Place 下单：校验地址和商品、快照、预占库存，在一个事务中完成
+	Place(ctx context.Context, req CreateOrderRequest) (*Order, error)
+	// Transition 按状态机执行订单状态流转，operatorID 为执行操作的用户
+	Transition(ctx context.Context, id uint, event OrderEvent, payMethod string, operatorID uint) (*Order, error)
+}
+
+// AddressRepository 收货地址数据访问
+type AddressRepository interface {
+	ListByUser(ctx context.Context, userID uint) ([]Address, error)
+	Create(ctx context.Context, userID uint, req AddressRequest) (*Address, error)
+	Update(ctx context.Context, userID, addressID uint, req AddressRequest) (*Address, error)
+	SetDefault(ctx context.Context, userID, addressID uint) (*Address, error)
+	Delete(ctx context.Context, userID, addressID uint) error
+}
+
+// CategoryRepository 分类数据访问
+type CategoryRepository interface {
+	Tree(ctx context.Context) ([]Category, error)
+	FindByID(ctx context.Context, id uint) (*Category, error)
+	// ResolveIDs 返回查询分类商品时需要匹配的分类 ID，includeDescendants 为 true 时包含子孙分类
+	ResolveIDs(ctx context.Context, id uint, includeDescendants bool) ([]uint, error)
+	Create(ctx context.Context, req CategoryRequest) (*Category, error)
+	Update(ctx context.Context, id uint, req CategoryRequest) (*Category, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// ReportRepository 销售报表数据访问，所有报表按下单时间过滤
+type ReportRepository interface {
+	Overview(ctx context.Context, created TimeRange) (*ReportOverview, error)
+	// SalesTrend 按 interval（day、week、month）分桶统计下单数和销售额
+	SalesTrend(ctx context.Context, created TimeRange, interval string) ([]SalesTrendRow, error)
+	// TopProducts 按 by（revenue、quantity）排序的商品排行
+	TopProducts(ctx context.Context, created TimeRange, by string, limit int) ([]TopProductRow, error)
+	TopCustomers(ctx context.Context, created TimeRange, limit int) ([]TopCustomerRow, error)
+	// SalesByRegion 按 level（province、city）统计地区销售额
+	SalesByRegion(ctx context.Context, created TimeRange, level string) ([]RegionSalesRow, error)
+}
+
+// Repositories 所有数据访问接口
+type Repositories struct {
+	Users      UserRepository
+	Products   ProductRepository
+	Orders     OrderRepository
+	Addresses  AddressRepository
+	Categories CategoryRepository
+	Reports    ReportRepository
+}
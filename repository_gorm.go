@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NewGormRepositories 创建基于 GORM 的数据访问实现
+func NewGormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:      &gormUserRepository{db: db},
+		Products:   &gormProductRepository{db: db},
+		Orders:     &gormOrderRepository{db: db},
+		Addresses:  &gormAddressRepository{db: db},
+		Categories: &gormCategoryRepository{db: db},
+		Reports:    &gormReportRepository{db: db},
+	}
+}
+
+// applyTimeRange 按时间区间过滤
+func applyTimeRange(query *gorm.DB, column string, r TimeRange) *gorm.DB {
+	if r.From != nil {
+		query = query.Where(column+" >= ?", *r.From)
+	}
+	if r.To != nil {
+		query = query.Where(column+" < ?", *r.To)
+	}
+	return query
+}
+
+// applyMoneyRange 按金额区间过滤
+func applyMoneyRange(query *gorm.DB, column string, r MoneyRange) *gorm.DB {
+	if r.Min != nil {
+		query = query.Where(column+" >= ?", *r.Min)
+	}
+	if r.Max != nil {
+		query = query.Where(column+" <= ?", *r.Max)
+	}
+	return query
+}
+
+// applyStatuses 按状态过滤，statuses 为空时不过滤
+func applyStatuses(query *gorm.DB, column string, statuses []int8) *gorm.DB {
+	if len(statuses) == 0 {
+		return query
+	}
+	return query.Where(column+" IN ?", statuses)
+}
+
+// gormUserRepository UserRepository 的 GORM 实现
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) List(ctx context.Context, filter UserFilter, params ListParams) (PageResult, error) {
+	query := applyStatuses(r.db.WithContext(ctx).Model(&User{}), "status", filter.Statuses)
+	query = applyTimeRange(query, "created_at", filter.Created)
+	return paginate[User](query, params)
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (*User, error) {
+	return r.first(r.db.WithContext(ctx), id)
+}
+
+func (r *gormUserRepository) FindByAccount(ctx context.Context, account string) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).Where("username = ? OR phone = ? OR email = ?", account, account, strings.ToLower(account)).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("查询用户失败: %v", err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindWithOrders(ctx context.Context, id uint) (*User, error) {
+	return r.first(r.db.WithContext(ctx).
+		// Preload("Orders") - 预加载用户的订单数据
+		// 作用：在查询用户时，同时查询该用户的所有订单
+		// 如果不使用 Preload，user.Orders 将为空（需要额外查询）
+		// SQL 执行：SELECT * FROM orders WHERE user_id = ?
+		Preload("Orders").
+		Preload("Addresses").
+		// Preload("Orders.OrderItems") - 嵌套预加载订单的商品明细
+		// "Orders.OrderItems" 表示：先加载 Orders，再加载每个 Order 的 OrderItems
+		// SQL 执行：SELECT * FROM order_items WHERE order_id IN (?, ?, ...)
+		Preload("Orders.OrderItems"), id)
+}
+
+func (r *gormUserRepository) FindWithOrderProducts(ctx context.Context, id uint) (*User, error) {
+	return r.first(r.db.WithContext(ctx).
+		Preload("Orders").
+		Preload("Orders.OrderItems").
+		Preload("Orders.OrderItems.Product"), id)
+}
+
+// Create 创建用户
+// 用户名、手机号、邮箱的唯一性检查包含已软删除的用户（唯一索引同样覆盖这些行）
+func (r *gormUserRepository) Create(ctx context.Context, user *User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		checks := []struct {
+			column string
+			value  string
+			err    error
+		}{
+			{"username", user.Username, ErrUsernameTaken},
+			{"phone", user.Phone, ErrPhoneTaken},
+			{"email", user.Email, ErrEmailTaken},
+		}
+		for _, check := range checks {
+			var count int64
+			if err := tx.Unscoped().Model(&User{}).Where(check.column+" = ?", check.value).Count(&count).Error; err != nil {
+				return fmt.Errorf("查询用户失败: %v", err)
+			}
+			if count > 0 {
+				return check.err
+			}
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			// 并发注册时唯一索引兜底
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrUserConflict
+			}
+			return fmt.Errorf("创建用户失败: %v", err)
+		}
+		return nil
+	})
+}
+
+func (r *gormUserRepository) UpdateRole(ctx context.Context, id uint, role string) (*User, error) {
+	user, err := r.first(r.db.WithContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Model(user).Update("role", role).Error; err != nil {
+		return nil, fmt.Errorf("修改用户角色失败: %v", err)
+	}
+	return user, nil
+}
+
+// first 按 ID 查询用户，不存在时返回 ErrUserNotFound
+func (r *gormUserRepository) first(query *gorm.DB, id uint) (*User, error) {
+	var user User
+	if err := query.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("查询用户失败: %v", err)
+	}
+	return &user, nil
+}
+
+// gormProductRepository ProductRepository 的 GORM 实现
+type gormProductRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormProductRepository) List(ctx context.Context, filter ProductFilter, params ListParams) (PageResult, error) {
+	query := applyStatuses(r.db.WithContext(ctx).Model(&Product{}), "status", filter.Statuses)
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.CategoryIDs != nil {
+		query = query.Where("category_id IN ?", filter.CategoryIDs)
+	}
+	query = applyMoneyRange(query, "price", filter.Price)
+	query = applyTimeRange(query, "created_at", filter.Created)
+	return paginate[Product](query, params)
+}
+
+func (r *gormProductRepository) FindByID(ctx context.Context, id uint) (*Product, error) {
+	return r.first(r.db.WithContext(ctx), id)
+}
+
+func (r *gormProductRepository) FindWithOrders(ctx context.Context, id uint) (*Product, error) {
+	return r.first(r.db.WithContext(ctx).
+		// 多对多关系的查询：商品 -> 订单明细 -> 订单
+		Preload("OrderItems").
+		Preload("OrderItems.Order").
+		Preload("OrderItems.Order.User"), id)
+}
+
+func (r *gormProductRepository) SalesStats(ctx context.Context, id uint, filter SalesStatsFilter) (*ProductSalesStats, error) {
+	db := r.db.WithContext(ctx)
+	product, err := r.first(db, id)
+	if err != nil {
+		return nil, err
+	}
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = salesOrderStatuses
+	}
+
+	// 在数据库中聚合，不把订单明细加载到内存
+	// 只查询计入销量的订单和已取消的订单，已取消的按是否支付过分别统计
+	query := func() *gorm.DB {
+		query := db.Table("order_items").
+			Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+			Where("order_items.product_id = ? AND order_items.deleted_at IS NULL", id).
+			Where("(orders.status IN ? OR orders.status = ?)", statuses, OrderStatusCancelled)
+		return applyTimeRange(query, "orders.created_at", filter.Created)
+	}
+	columns := `COALESCE(SUM(CASE WHEN orders.status IN @sold THEN order_items.quantity ELSE 0 END), 0) AS total_quantity,
+		COALESCE(SUM(CASE WHEN orders.status IN @sold THEN order_items.subtotal ELSE 0 END), 0) AS total_amount,
+		COUNT(DISTINCT CASE WHEN orders.status IN @sold THEN order_items.order_id END) AS order_count,
+		COALESCE(SUM(CASE WHEN orders.status = @cancelled AND orders.pay_time IS NOT NULL THEN order_items.quantity ELSE 0 END), 0) AS refunded_quantity,
+		COALESCE(SUM(CASE WHEN orders.status = @cancelled AND orders.pay_time IS NOT NULL THEN order_items.subtotal ELSE 0 END), 0) AS refunded_amount,
+		COALESCE(SUM(CASE WHEN orders.status = @cancelled AND orders.pay_time IS NULL THEN order_items.quantity ELSE 0 END), 0) AS cancelled_quantity`
+	args := map[string]interface{}{"sold": statuses, "cancelled": OrderStatusCancelled}
+
+	var total salesRow
+	if err := query().Select(columns, args).Scan(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计商品销量失败: %v", err)
+	}
+	stats := ProductSalesStats{Product: product, Statuses: statuses, SalesFigures: total.figures()}
+
+	if filter.Interval != "" {
+		period, err := salesPeriodExpr(db.Dialector.Name(), filter.Interval)
+		if err != nil {
+			return nil, err
+		}
+		var rows []salesRow
+		err = query().Select(period+" AS period, "+columns, args).
+			Group("period").Order("period").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("按时间统计商品销量失败: %v", err)
+		}
+		stats.Interval = filter.Interval
+		stats.Buckets = make([]SalesBucket, 0, len(rows))
+		for _, row := range rows {
+			stats.Buckets = append(stats.Buckets, SalesBucket{Period: row.Period, SalesFigures: row.figures()})
+		}
+	}
+	return &stats, nil
+}
+
+// salesRow 销售统计的查询结果
+type salesRow struct {
+	Period            string
+	TotalQuantity     int
+	TotalAmount       Money
+	OrderCount        int
+	RefundedQuantity  int
+	RefundedAmount    Money
+	CancelledQuantity int
+}
+
+func (row salesRow) figures() SalesFigures {
+	figures := SalesFigures{
+		TotalQuantity:     row.TotalQuantity,
+		TotalAmount:       row.TotalAmount,
+		OrderCount:        row.OrderCount,
+		RefundedQuantity:  row.RefundedQuantity,
+		RefundedAmount:    row.RefundedAmount,
+		CancelledQuantity: row.CancelledQuantity,
+	}
+	if figures.OrderCount > 0 {
+		figures.AverageAmount = figures.TotalAmount.Div(figures.OrderCount)
+	}
+	return figures
+}
+
+// salesPeriodExpr 返回下单时间所在分桶开始日期的 SQL 表达式（YYYY-MM-DD 格式的字符串）
+// 各数据库的日期函数不同；分桶按数据库会话的时区计算
+func salesPeriodExpr(dialect, interval string) (string, error) {
+	expressions := map[string]map[string]string{
+		DriverMySQL: {
+			SalesIntervalDay:   "DATE_FORMAT(orders.created_at, '%Y-%m-%d')",
+			SalesIntervalWeek:  "DATE_FORMAT(DATE_SUB(orders.created_at, INTERVAL WEEKDAY(orders.created_at) DAY), '%Y-%m-%d')",
+			SalesIntervalMonth: "DATE_FORMAT(orders.created_at, '%Y-%m-01')",
+		},
+		DriverPostgres: {
+			SalesIntervalDay:   "to_char(date_trunc('day', orders.created_at), 'YYYY-MM-DD')",
+			SalesIntervalWeek:  "to_char(date_trunc('week', orders.created_at), 'YYYY-MM-DD')",
+			SalesIntervalMonth: "to_char(date_trunc('month', orders.created_at), 'YYYY-MM-DD')",
+		},
+		DriverSQLite: {
+			SalesIntervalDay:   "strftime('%Y-%m-%d', orders.created_at)",
+			SalesIntervalWeek:  "strftime('%Y-%m-%d', orders.created_at, 'weekday 0', '-6 days')",
+			SalesIntervalMonth: "strftime('%Y-%m-01', orders.created_at)",
+		},
+	}
+	expr, ok := expressions[dialect][interval]
+	if !ok {
+		return "", fmt.Errorf("不支持的统计粒度: %s（数据库 %s）", interval, dialect)
+	}
+	return expr, nil
+}
+
+// StockHistory 先确认商品存在，再按类型、关联订单和时间过滤该商品的库存流水
+func (r *gormProductRepository) StockHistory(ctx context.Context, id uint, filter StockMovementFilter, params ListParams) (PageResult, error) {
+	db := r.db.WithContext(ctx)
+	if _, err := r.first(db, id); err != nil {
+		return PageResult{}, err
+	}
+	query := db.Model(&StockMovement{}).Where("product_id = ?", id)
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.OrderID != nil {
+		query = query.Where("order_id = ?", *filter.OrderID)
+	}
+	query = applyTimeRange(query, "created_at", filter.Created)
+	return paginate[StockMovement](query, params)
+}
+
+// AdjustStock 人工调整库存，校验和写入流水见 adjustStock
+func (r *gormProductRepository) AdjustStock(ctx context.Context, id uint, req StockAdjustmentRequest, operatorID uint) (*StockMovement, error) {
+	return adjustStock(r.db.WithContext(ctx), id, req, operatorID)
+}
+
+// first 按 ID 查询商品，不存在时返回 ErrProductNotFound
+func (r *gormProductRepository) first(query *gorm.DB, id uint) (*Product, error) {
+	var product Product
+	if err := query.First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("查询商品失败: %v", err)
+	}
+	return &product, nil
+}
+
+// gormOrderRepository OrderRepository 的 GORM 实现
+type gormOrderRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormOrderRepository) List(ctx context.Context, filter OrderFilter, params ListParams) (PageResult, error) {
+	query := applyStatuses(r.db.WithContext(ctx).Model(&Order{}), "status", filter.Statuses)
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	query = applyTimeRange(query, "created_at", filter.Created)
+
+	// 只预加载当前页订单的明细，不再加载每个订单的用户信息
+	return paginate[Order](query, params, "OrderItems")
+}
+
+func (r *gormOrderRepository) FindByID(ctx context.Context, id uint) (*Order, error) {
+	return r.first(r.db.WithContext(ctx), id)
+}
+
+func (r *gormOrderRepository) FindDetail(ctx context.Context, id uint) (*Order, error) {
+	return r.first(r.db.WithContext(ctx).
+		Preload("User").
+		// 多对多关系的查询：订单 -> 订单明细 -> 商品
+		Preload("OrderItems").
+		Preload("OrderItems.Product"), id)
+}
+
+func (r *gormOrderRepository) Place(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	return placeOrder(r.db.WithContext(ctx), req)
+}
+
+func (r *gormOrderRepository) Transition(ctx context.Context, id uint, event OrderEvent, payMethod string, operatorID uint) (*Order, error) {
+	return transitionOrder(r.db.WithContext(ctx), id, event, payMethod, operatorID)
+}
+
+// first 按 ID 查询订单，不存在时返回 ErrOrderNotFound
+func (r *gormOrderRepository) first(query *gorm.DB, id uint) (*Order, error) {
+	var order Order
+	if err := query.First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("查询订单失败: %v", err)
+	}
+	return &order, nil
+}
+
+// gormAddressRepository AddressRepository 的 GORM 实现
+type gormAddressRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAddressRepository) ListByUser(ctx context.Context, userID uint) ([]Address, error) {
+	return listAddresses(r.db.WithContext(ctx), userID)
+}
+
+func (r *gormAddressRepository) Create(ctx context.Context, userID uint, req AddressRequest) (*Address, error) {
+	return createAddress(r.db.WithContext(ctx), userID, req)
+}
+
+func (r *gormAddressRepository) Update(ctx context.Context, userID, addressID uint, req AddressRequest) (*Address, error) {
+	return updateAddress(r.db.WithContext(ctx), userID, addressID, req)
+}
+
+func (r *gormAddressRepository) SetDefault(ctx context.Context, userID, addressID uint) (*Address, error) {
+	return setDefaultAddress(r.db.WithContext(ctx), userID, addressID)
+}
+
+func (r *gormAddressRepository) Delete(ctx context.Context, userID, addressID uint) error {
+	return deleteAddress(r.db.WithContext(ctx), userID, addressID)
+}
+
+// gormCategoryRepository CategoryRepository 的 GORM 实现
+type gormCategoryRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormCategoryRepository) Tree(ctx context.Context) ([]Category, error) {
+	return queryCategoryTree(r.db.WithContext(ctx))
+}
+
+func (r *gormCategoryRepository) FindByID(ctx context.Context, id uint) (*Category, error) {
+	var category Category
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("查询分类失败: %v", err)
+	}
+	return &category, nil
+}
+
+func (r *gormCategoryRepository) ResolveIDs(ctx context.Context, id uint, includeDescendants bool) ([]uint, error) {
+	return resolveCategoryIDs(r.db.WithContext(ctx), id, includeDescendants)
+}
+
+func (r *gormCategoryRepository) Create(ctx context.Context, req CategoryRequest) (*Category, error) {
+	return createCategory(r.db.WithContext(ctx), req)
+}
+
+func (r *gormCategoryRepository) Update(ctx context.Context, id uint, req CategoryRequest) (*Category, error) {
+	return updateCategory(r.db.WithContext(ctx), id, req)
+}
+
+func (r *gormCategoryRepository) Delete(ctx context.Context, id uint) error {
+	return deleteCategory(r.db.WithContext(ctx), id)
+}
+
+// gormReportRepository ReportRepository 的 GORM 实现，查询见 report_service.go
+type gormReportRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormReportRepository) Overview(ctx context.Context, created TimeRange) (*ReportOverview, error) {
+	return queryReportOverview(r.db.WithContext(ctx), created)
+}
+
+func (r *gormReportRepository) SalesTrend(ctx context.Context, created TimeRange, interval string) ([]SalesTrendRow, error) {
+	return querySalesTrend(r.db.WithContext(ctx), created, interval)
+}
+
+func (r *gormReportRepository) TopProducts(ctx context.Context, created TimeRange, by string, limit int) ([]TopProductRow, error) {
+	return queryTopProducts(r.db.WithContext(ctx), created, by, limit)
+}
+
+func (r *gormReportRepository) TopCustomers(ctx context.Context, created TimeRange, limit int) ([]TopCustomerRow, error) {
+	return queryTopCustomers(r.db.WithContext(ctx), created, limit)
+}
+
+func (r *gormReportRepository) SalesByRegion(ctx context.Context, created TimeRange, level string) ([]RegionSalesRow, error) {
+	return querySalesByRegion(r.db.WithContext(ctx), created, level)
+}
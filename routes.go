@@ -1,60 +1,129 @@
 package main
 
 import (
+	"log/slog"
+
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes 设置路由
-func SetupRoutes() *gin.Engine {
-	// 创建 Gin 引擎
-	r := gin.Default()
+func SetupRoutes(s *Server, config ServerConfig) *gin.Engine {
+	// 创建 Gin 引擎，请求日志和 panic 恢复使用结构化日志代替 gin 默认的输出
+	// 链路追踪放在最前面，请求日志和 panic 日志中可以带上 trace_id
+	r := gin.New()
+	log := slog.Default()
+	r.Use(Tracing(), RequestID(), RequestLogger(log), Recovery(log))
 
-	// 添加 CORS 中间件（如果需要跨域访问）
-	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+	// Prometheus 指标
+	if s.metrics != nil {
+		r.Use(s.metrics.Middleware())
+		r.GET("/metrics", s.metrics.Handler())
+	}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// 添加 CORS 中间件，允许的来源由 server.cors_origins 配置
+	r.Use(CORS(config.CORSOrigins))
 
-		c.Next()
-	})
+	// 存活、就绪探针
+	r.GET("/livez", s.Livez)
+	r.GET("/readyz", s.Readyz)
 
-	// 健康检查路由
+	// 健康检查路由（保留兼容，不检查数据库，部署时请使用 /livez 和 /readyz）
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status": "ok",
+			"status":  "ok",
 			"message": "服务运行正常",
 		})
-	})	
-	
+	})
+
+	// 认证相关路由（无需登录）
+	r.POST("/auth/register", s.Register)    // 用户注册
+	r.POST("/auth/login", s.Login)          // 用户登录
+	r.POST("/auth/refresh", s.RefreshToken) // 刷新令牌
+
+	// 公开的商品、分类查询路由（无需登录）
+	r.GET("/products", s.GetProducts)                        // 查询所有商品
+	r.GET("/products/:id", s.GetProduct)                     // 查询单个商品
+	r.GET("/categories", s.GetCategories)                    // 查询分类树
+	r.GET("/categories/:id", s.GetCategory)                  // 查询单个分类
+	r.GET("/categories/:id/products", s.GetCategoryProducts) // 查询分类下的商品（含子孙分类）
+
+	// 以下路由需要登录，已禁用的用户会被拒绝
+	auth := r.Group("/", s.AuthRequired())
+
+	auth.GET("/auth/me", s.GetCurrentUser) // 查询当前登录用户
+
 	// 测试数据接口
-	r.POST("/seed", SeedData) // 插入测试数据
+	auth.POST("/seed", RequirePolicy(canSeedData), s.SeedData) // 插入测试数据
 
 	// 用户相关路由
-	r.GET("/users", GetUsers)                           // 查询所有用户
-	r.GET("/users/:id/orders", GetUserOrders)            // 查询用户的订单
-	r.GET("/users/:id/orders/products", GetUserOrdersWithProducts) // 查询用户的订单及商品
+	auth.GET("/users", RequirePolicy(canListUsers), s.GetUsers)                  // 查询所有用户
+	auth.GET("/users/:id/orders", s.GetUserOrders)                               // 查询用户的订单
+	auth.GET("/users/:id/orders/products", s.GetUserOrdersWithProducts)          // 查询用户的订单及商品
+	auth.PUT("/users/:id/role", RequirePolicy(canManageUsers), s.UpdateUserRole) // 修改用户角色
 
-	// 商品相关路由
-	r.GET("/products", GetProducts)                     // 查询所有商品
-	r.GET("/products/:id", GetProduct)                   // 查询单个商品
-	r.GET("/products/:id/orders", GetProductOrders)      // 查询商品被哪些订单购买
-	r.GET("/products/:id/stats", GetProductSalesStats)   // 查询商品销售统计
-
-	// 订单相关路由
-	r.GET("/orders", GetOrders)                          // 查询所有订单
-	r.GET("/orders/:id", GetOrder)                       // 查询单个订单
-	r.GET("/orders/:id/products", GetOrderProducts)       // 查询订单包含哪些商品
+	// 收货地址相关路由
+	auth.GET("/users/:id/addresses", s.GetUserAddresses)                // 查询用户的收货地址
+	auth.POST("/users/:id/addresses", s.CreateAddress)                  // 创建收货地址
+	auth.PUT("/users/:id/addresses/:aid", s.UpdateAddress)              // 修改收货地址
+	auth.DELETE("/users/:id/addresses/:aid", s.DeleteAddress)           // 删除收货地址
+	auth.POST("/users/:id/addresses/:aid/default", s.SetDefaultAddress) // 设为默认地址
 
+	// 商品相关路由
+	auth.GET("/products/:id/orders", RequirePolicy(canViewSalesData), s.GetProductOrders)              // 查询商品被哪些订单购买
+	auth.GET("/products/:id/stats", RequirePolicy(canViewSalesData), s.GetProductSalesStats)           // 查询商品销售统计
+	auth.GET("/products/:id/stock-history", RequirePolicy(canViewSalesData), s.GetProductStockHistory) // 查询商品库存流水
+	auth.POST("/products/:id/stock-adjustments", RequirePolicy(canAdjustStock), s.AdjustProductStock)  // 人工调整库存（盘点、进货、退货）
 
+	// 分类相关路由
+	auth.POST("/categories", RequirePolicy(canManageCatalog), s.CreateCategory)       // 创建分类
+	auth.PUT("/categories/:id", RequirePolicy(canManageCatalog), s.UpdateCategory)    // 修改分类
+	auth.DELETE("/categories/:id", RequirePolicy(canManageCatalog), s.DeleteCategory) // 删除分类
 
+	// 销售报表路由，支持 start_date/end_date 和 format=csv
+	reports := auth.Group("/reports", RequirePolicy(canViewSalesData))
+	reports.GET("/overview", s.GetReportOverview)    // 销售概览、平均每单金额、支付转化率
+	reports.GET("/sales", s.GetSalesTrend)           // 按天、周、月统计销售额和订单数
+	reports.GET("/top-products", s.GetTopProducts)   // 商品排行
+	reports.GET("/top-customers", s.GetTopCustomers) // 客户排行
+	reports.GET("/regions", s.GetSalesByRegion)      // 按省份、城市统计销售额
 
+	// 订单相关路由
+	auth.GET("/orders", s.GetOrders)                     // 查询所有订单
+	auth.GET("/orders/:id", s.GetOrder)                  // 查询单个订单
+	auth.GET("/orders/:id/products", s.GetOrderProducts) // 查询订单包含哪些商品
+	auth.POST("/orders", s.CreateOrder)                  // 创建订单
+	auth.POST("/orders/:id/pay", s.PayOrder)             // 支付订单
+	auth.POST("/orders/:id/ship", s.ShipOrder)           // 订单发货
+	auth.POST("/orders/:id/complete", s.CompleteOrder)   // 订单确认收货
+	auth.POST("/orders/:id/cancel", s.CancelOrder)       // 取消订单
 
 	return r
 }
 
+// CORS 跨域中间件
+// origins 包含 "*" 时允许所有来源；否则只为列表中的来源返回跨域响应头，其他来源由浏览器拦截
+func CORS(origins []string) gin.HandlerFunc {
+	allowAll := contains(origins, "*")
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		origin := c.GetHeader("Origin")
+		switch {
+		case allowAll:
+			header.Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && contains(origins, origin):
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+			header.Add("Vary", "Origin")
+		}
+		header.Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		header.Set("Access-Control-Expose-Headers", requestIDHeader)
+		header.Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,273 +1,699 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// seedUserPassword 测试用户的登录密码
+const seedUserPassword = "password123"
+
+// 测试数据的默认数量和上限
+const (
+	defaultSeedUsers     = 20
+	defaultSeedAddresses = 3
+	defaultSeedProducts  = 40
+	defaultSeedOrders    = 200
+
+	maxSeedUsers     = 10000
+	maxSeedAddresses = 5
+	maxSeedProducts  = 10000
+	maxSeedOrders    = 100000
+
+	// seedBatchSize 批量插入时每批的行数，避免超过数据库单条语句的占位符上限
+	seedBatchSize = 200
 )
 
-// seedData 插入测试数据
-func seedData() error {
-	fmt.Println("开始插入测试数据...")
-
-	// 1. 插入用户数据
-	users := []User{
-		{
-			Username: "zhangsan",
-			Phone:    "13800138001",
-			Email:    "zhangsan@example.com",
-			Password: "hashed_password_123",
-			Nickname: "张三",
-			Avatar:   "https://example.com/avatar/zhangsan.jpg",
-			Status:   UserStatusNormal,
-		},
-		{
-			Username: "lisi",
-			Phone:    "13800138002",
-			Email:    "lisi@example.com",
-			Password: "hashed_password_456",
-			Nickname: "李四",
-			Avatar:   "https://example.com/avatar/lisi.jpg",
-			Status:   UserStatusNormal,
-		},
-		{
-			Username: "wangwu",
-			Phone:    "13800138003",
-			Email:    "wangwu@example.com",
-			Password: "hashed_password_789",
-			Nickname: "王五",
-			Avatar:   "https://example.com/avatar/wangwu.jpg",
-			Status:   UserStatusNormal,
-		},
-	}
-
-	if err := db.Create(&users).Error; err != nil {
+// ErrInvalidSeedOptions 测试数据生成参数无效
+var ErrInvalidSeedOptions = errors.New("无效的测试数据参数")
+
+// SeedOptions 测试数据生成参数，数量为 0 时使用默认值
+// 相同的 Seed 生成相同的数据（时间字段相对当前时间生成）
+type SeedOptions struct {
+	Users     int   `form:"users"`     // 用户数量
+	Addresses int   `form:"addresses"` // 每个用户最多的收货地址数量，每个用户至少一个
+	Products  int   `form:"products"`  // 商品数量
+	Orders    int   `form:"orders"`    // 订单数量，库存不足时实际生成的数量可能更少
+	Seed      int64 `form:"seed"`      // 随机数种子
+	Reset     bool  `form:"reset"`     // 先清空已有的业务数据（保留管理员和运营账号）再生成
+}
+
+// SeedResult 测试数据生成结果
+type SeedResult struct {
+	Seed       int64    `json:"seed"`
+	Skipped    bool     `json:"skipped"` // 数据库中已有数据，没有插入
+	Users      int      `json:"users"`
+	Addresses  int      `json:"addresses"`
+	Categories int      `json:"categories"`
+	Products   int      `json:"products"`
+	Orders     int      `json:"orders"`
+	OrderItems int      `json:"order_items"`
+	Accounts   []string `json:"accounts,omitempty"` // 部分生成的用户名，密码均为 seedUserPassword
+}
+
+// normalize 填充默认值并校验参数范围
+func (o SeedOptions) normalize() (SeedOptions, error) {
+	defaults := []struct {
+		value    *int
+		fallback int
+		max      int
+		name     string
+	}{
+		{&o.Users, defaultSeedUsers, maxSeedUsers, "users"},
+		{&o.Addresses, defaultSeedAddresses, maxSeedAddresses, "addresses"},
+		{&o.Products, defaultSeedProducts, maxSeedProducts, "products"},
+		{&o.Orders, defaultSeedOrders, maxSeedOrders, "orders"},
+	}
+	for _, d := range defaults {
+		if *d.value == 0 {
+			*d.value = d.fallback
+		}
+		if *d.value < 0 || *d.value > d.max {
+			return o, fmt.Errorf("%w: %s 必须在 1 到 %d 之间", ErrInvalidSeedOptions, d.name, d.max)
+		}
+	}
+	return o, nil
+}
+
+// seedData 按参数生成测试数据
+// 数据库中已有商品或订单时跳过插入，重复调用不会因为唯一索引冲突而失败；Reset 为 true 时先清空再生成
+// 所有数据在一个事务中插入，失败时不会留下部分数据
+func seedData(db *gorm.DB, opts SeedOptions) (*SeedResult, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	// 测试用户的密码统一为 seedUserPassword，使用 bcrypt 加密后保存
+	passwordHash, err := hashPassword(seedUserPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeedResult{Seed: opts.Seed}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if opts.Reset {
+			if err := resetSeedData(tx); err != nil {
+				return err
+			}
+		} else {
+			exists, err := hasBusinessData(tx)
+			if err != nil {
+				return err
+			}
+			if exists {
+				result.Skipped = true
+				return nil
+			}
+		}
+
+		set := generateSeedSet(opts, time.Now())
+		for i := range set.users {
+			set.users[i].Password = passwordHash
+		}
+		return set.insert(tx, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// hasBusinessData 数据库中是否已有商品或订单（包括软删除的记录，它们仍然占用唯一索引）
+func hasBusinessData(tx *gorm.DB) (bool, error) {
+	for _, model := range []interface{}{&Product{}, &Order{}} {
+		var count int64
+		if err := tx.Unscoped().Model(model).Count(&count).Error; err != nil {
+			return false, fmt.Errorf("查询已有数据失败: %v", err)
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resetSeedData 物理删除所有业务数据和顾客账号，保留管理员和运营账号
+// 按外键依赖顺序删除：库存流水 -> 库存预占 -> 订单明细 -> 订单 -> 地址 -> 商品 -> 分类 -> 顾客
+func resetSeedData(tx *gorm.DB) error {
+	steps := []struct {
+		name  string
+		query *gorm.DB
+		model interface{}
+	}{
+		{"库存流水", tx.Where("1 = 1"), &StockMovement{}},
+		{"库存预占记录", tx.Where("1 = 1"), &StockReservation{}},
+		{"订单明细", tx.Unscoped().Where("1 = 1"), &OrderItem{}},
+		{"订单", tx.Unscoped().Where("1 = 1"), &Order{}},
+		{"收货地址", tx.Unscoped().Where("1 = 1"), &Address{}},
+		{"商品", tx.Unscoped().Where("1 = 1"), &Product{}},
+	}
+	for _, step := range steps {
+		if err := step.query.Delete(step.model).Error; err != nil {
+			return fmt.Errorf("清空%s失败: %v", step.name, err)
+		}
+	}
+
+	// 分类之间有上级分类外键，先断开层级关系再删除
+	if err := tx.Unscoped().Model(&Category{}).Where("parent_id IS NOT NULL").Update("parent_id", nil).Error; err != nil {
+		return fmt.Errorf("清空分类失败: %v", err)
+	}
+	if err := tx.Unscoped().Where("1 = 1").Delete(&Category{}).Error; err != nil {
+		return fmt.Errorf("清空分类失败: %v", err)
+	}
+
+	if err := tx.Unscoped().Where("role = ?", RoleCustomer).Delete(&User{}).Error; err != nil {
+		return fmt.Errorf("清空顾客账号失败: %v", err)
+	}
+	return nil
+}
+
+// seedSet 在内存中生成的一组测试数据，关联关系用切片下标表示，插入数据库时再换成 ID
+type seedSet struct {
+	categories []seedCategory
+	users      []User
+	addresses  []seedAddress
+	userAddrs  [][]int // 每个用户的地址下标，第一个是默认地址
+	products   []seedProduct
+	orders     []seedOrder
+}
+
+type seedCategory struct {
+	Category
+	parent int // 上级分类下标，-1 表示顶级分类
+}
+
+type seedAddress struct {
+	Address
+	user int
+}
+
+type seedProduct struct {
+	Product
+	category int
+}
+
+type seedOrder struct {
+	Order
+	user    int
+	address int
+	items   []seedOrderItem
+}
+
+type seedOrderItem struct {
+	OrderItem
+	product int
+}
+
+// insert 按依赖顺序插入数据库，并把数量写入 result
+func (s *seedSet) insert(tx *gorm.DB, result *SeedResult) error {
+	// 1. 分类：上级分类排在子分类前面，逐个插入以便回填上级分类 ID
+	for i := range s.categories {
+		category := &s.categories[i]
+		if category.parent >= 0 {
+			category.ParentID = &s.categories[category.parent].ID
+		}
+		if err := tx.Create(&category.Category).Error; err != nil {
+			return fmt.Errorf("插入分类数据失败: %v", err)
+		}
+	}
+
+	// 2. 用户
+	if err := tx.CreateInBatches(s.users, seedBatchSize).Error; err != nil {
 		return fmt.Errorf("插入用户数据失败: %v", err)
 	}
-	
-	// 验证 ID 是否被正确填充
-	for i, user := range users {
-		if user.ID == 0 {
-			return fmt.Errorf("用户 %d 的 ID 未正确填充", i)
-		}
-	}
-	fmt.Printf("✓ 成功插入 %d 个用户 (ID: %d, %d, %d)\n", len(users), users[0].ID, users[1].ID, users[2].ID)
-
-	// 2. 插入地址数据
-	addresses := []Address{
-		{
-			UserID:        users[0].ID,
-			ReceiverName:  "张三",
-			ReceiverPhone: "13800138001",
-			Province:      "北京市",
-			City:          "北京市",
-			District:      "海淀区",
-			Detail:        "中关村大街1号",
-			PostalCode:    "100080",
-			IsDefault:     true,
-		},
-		{
-			UserID:        users[0].ID,
-			ReceiverName:  "张三",
-			ReceiverPhone: "13800138001",
-			Province:      "北京市",
-			City:          "北京市",
-			District:      "朝阳区",
-			Detail:        "三里屯路2号",
-			PostalCode:    "100027",
-			IsDefault:     false,
-		},
-		{
-			UserID:        users[1].ID,
-			ReceiverName:  "李四",
-			ReceiverPhone: "13800138002",
-			Province:      "上海市",
-			City:          "上海市",
-			District:      "浦东新区",
-			Detail:        "陆家嘴环路1000号",
-			PostalCode:    "200120",
-			IsDefault:     true,
-		},
-	}
-
-	if err := db.Create(&addresses).Error; err != nil {
+
+	// 3. 地址
+	addresses := make([]Address, len(s.addresses))
+	for i, address := range s.addresses {
+		address.UserID = s.users[address.user].ID
+		addresses[i] = address.Address
+	}
+	if err := tx.CreateInBatches(addresses, seedBatchSize).Error; err != nil {
 		return fmt.Errorf("插入地址数据失败: %v", err)
 	}
-	
-	// 验证地址 ID 是否被正确填充
-	for i, address := range addresses {
-		if address.ID == 0 {
-			return fmt.Errorf("地址 %d 的 ID 未正确填充", i)
-		}
-	}
-	fmt.Printf("✓ 成功插入 %d 个地址 (ID: %d, %d, %d)\n", len(addresses), addresses[0].ID, addresses[1].ID, addresses[2].ID)
-
-	// 3. 插入商品数据
-	products := []Product{
-		{
-			ProductNo:   generateProductNo(1),
-			Name:        "iPhone 15 Pro",
-			Description: "苹果最新款手机，A17 Pro芯片，6.1英寸屏幕",
-			CategoryID:  1,
-			Price:       7999.00,
-			Stock:       100,
-			Sales:       0,
-			Image:       "https://example.com/images/iphone15pro.jpg",
-			Status:      ProductStatusOnSale,
-			Sort:        1,
-		},
-		{
-			ProductNo:   generateProductNo(2),
-			Name:        "AirPods Pro",
-			Description: "苹果无线降噪耳机，主动降噪，空间音频",
-			CategoryID:  2,
-			Price:       1899.00,
-			Stock:       200,
-			Sales:       0,
-			Image:       "https://example.com/images/airpodspro.jpg",
-			Status:      ProductStatusOnSale,
-			Sort:        2,
-		},
-		{
-			ProductNo:   generateProductNo(3),
-			Name:        "MacBook Pro 14英寸",
-			Description: "苹果笔记本电脑，M3芯片，14英寸Liquid Retina XDR显示屏",
-			CategoryID:  3,
-			Price:       14999.00,
-			Stock:       50,
-			Sales:       0,
-			Image:       "https://example.com/images/macbookpro14.jpg",
-			Status:      ProductStatusOnSale,
-			Sort:        3,
-		},
-		{
-			ProductNo:   generateProductNo(4),
-			Name:        "手机保护壳",
-			Description: "iPhone 15 Pro专用保护壳，防摔防刮",
-			CategoryID:  4,
-			Price:       99.00,
-			Stock:       500,
-			Sales:       0,
-			Image:       "https://example.com/images/phonecase.jpg",
-			Status:      ProductStatusOnSale,
-			Sort:        4,
-		},
-	}
-
-	if err := db.Create(&products).Error; err != nil {
+
+	// 4. 商品
+	products := make([]Product, len(s.products))
+	for i, product := range s.products {
+		product.CategoryID = &s.categories[product.category].ID
+		products[i] = product.Product
+	}
+	if err := tx.CreateInBatches(products, seedBatchSize).Error; err != nil {
 		return fmt.Errorf("插入商品数据失败: %v", err)
 	}
-	
-	// 验证商品 ID 是否被正确填充
+
+	// 5. 订单（快照收货地址）
+	orders := make([]Order, len(s.orders))
+	for i, order := range s.orders {
+		order.UserID = s.users[order.user].ID
+		order.AddressID = addresses[order.address].ID
+		snapshotAddress(&order.Order, addresses[order.address])
+		orders[i] = order.Order
+	}
+	if len(orders) > 0 {
+		if err := tx.CreateInBatches(orders, seedBatchSize).Error; err != nil {
+			return fmt.Errorf("插入订单数据失败: %v", err)
+		}
+	}
+
+	// 6. 订单明细
+	var orderItems []OrderItem
+	for i, order := range s.orders {
+		for _, item := range order.items {
+			item.OrderID = orders[i].ID
+			item.ProductID = products[item.product].ID
+			item.CreatedAt = orders[i].CreatedAt
+			orderItems = append(orderItems, item.OrderItem)
+		}
+	}
+	if len(orderItems) > 0 {
+		if err := tx.CreateInBatches(orderItems, seedBatchSize).Error; err != nil {
+			return fmt.Errorf("插入订单明细数据失败: %v", err)
+		}
+	}
+
+	// 7. 库存预占记录，状态与订单一致：待支付的已预占，已支付的已扣减，已取消的已释放
+	var reservations []StockReservation
+	for i, order := range s.orders {
+		status := ReservationStatusDeducted
+		switch order.Status {
+		case OrderStatusPending:
+			status = ReservationStatusReserved
+		case OrderStatusCancelled:
+			status = ReservationStatusReleased
+		}
+		for _, item := range order.items {
+			reservations = append(reservations, StockReservation{
+				OrderID:   orders[i].ID,
+				ProductID: products[item.product].ID,
+				Quantity:  item.Quantity,
+				Status:    status,
+				CreatedAt: orders[i].CreatedAt,
+				UpdatedAt: orders[i].UpdatedAt,
+			})
+		}
+	}
+	if len(reservations) > 0 {
+		if err := tx.CreateInBatches(reservations, seedBatchSize).Error; err != nil {
+			return fmt.Errorf("插入库存预占数据失败: %v", err)
+		}
+	}
+
+	// 8. 库存流水：每个商品一条期初记录，从零变为生成后的库存、预占数量和销量，之后的变动都记录在流水中
+	movements := make([]StockMovement, len(products))
 	for i, product := range products {
-		if product.ID == 0 {
-			return fmt.Errorf("商品 %d 的 ID 未正确填充", i)
-		}
-	}
-	fmt.Printf("✓ 成功插入 %d 个商品 (ID: %d, %d, %d, %d)\n", len(products), products[0].ID, products[1].ID, products[2].ID, products[3].ID)
-
-	// 4. 插入订单数据(用户、关联商品、地址)
-	now := time.Now()
-	payTime := now.Add(10 * time.Minute)
-	orders := []Order{
-		{
-			OrderNo:       generateOrderNo(),
-			UserID:        users[0].ID,
-			AddressID:     addresses[0].ID,
-			TotalAmount:   7999.00,
-			DiscountAmount: 0.00,
-			PayAmount:     7999.00,
-			Status:        OrderStatusPaid,
-			PayMethod:     "支付宝",
-			PayTime:       &payTime,
-			Remark:        "请尽快发货",
-		},
-		{
-			OrderNo:       generateOrderNo(),
-			UserID:        users[0].ID,
-			AddressID:     addresses[0].ID,
-			TotalAmount:   1998.00,
-			DiscountAmount: 99.00,
-			PayAmount:     1899.00,
-			Status:        OrderStatusPending,
-			PayMethod:     "",
-			Remark:        "",
-		},
-		{
-			OrderNo:       generateOrderNo(),
-			UserID:        users[1].ID,
-			AddressID:     addresses[2].ID,
-			TotalAmount:   14999.00,
-			DiscountAmount: 0.00,
-			PayAmount:     14999.00,
-			Status:        OrderStatusShipped,
-			PayMethod:     "微信支付",
-			PayTime:       &payTime,
-			ShipTime:      &payTime,
-			Remark:        "公司地址，工作日配送",
-		},
-	}
-
-	if err := db.Create(&orders).Error; err != nil {
-		return fmt.Errorf("插入订单数据失败: %v", err)
-	}
-	
-	// 验证订单 ID 是否被正确填充
-	for i, order := range orders {
-		if order.ID == 0 {
-			return fmt.Errorf("订单 %d 的 ID 未正确填充", i)
-		}
-	}
-	fmt.Printf("✓ 成功插入 %d 个订单 (ID: %d, %d, %d)\n", len(orders), orders[0].ID, orders[1].ID, orders[2].ID)
-
-	// 5. 插入订单明细数据
-	orderItems := []OrderItem{
-		// 订单1：iPhone 15 Pro × 1
-		{
-			OrderID:      orders[0].ID,
-			ProductID:    products[0].ID,
-			ProductName:  products[0].Name,
-			ProductImage: products[0].Image,
-			Price:        products[0].Price,
-			Quantity:     1,
-			Subtotal:     products[0].Price * 1,
-		},
-		// 订单2：AirPods Pro × 1 + 手机保护壳 × 1
-		{
-			OrderID:      orders[1].ID,
-			ProductID:    products[1].ID,
-			ProductName:  products[1].Name,
-			ProductImage: products[1].Image,
-			Price:        products[1].Price,
-			Quantity:     1,
-			Subtotal:     products[1].Price * 1,
-		},
-		{
-			OrderID:      orders[1].ID,
-			ProductID:    products[3].ID,
-			ProductName:  products[3].Name,
-			ProductImage: products[3].Image,
-			Price:        products[3].Price,
-			Quantity:     1,
-			Subtotal:     products[3].Price * 1,
-		},
-		// 订单3：MacBook Pro × 1
-		{
-			OrderID:      orders[2].ID,
-			ProductID:    products[2].ID,
-			ProductName:  products[2].Name,
-			ProductImage: products[2].Image,
-			Price:        products[2].Price,
-			Quantity:     1,
-			Subtotal:     products[2].Price * 1,
-		},
-	}
-
-	if err := db.Create(&orderItems).Error; err != nil {
-		return fmt.Errorf("插入订单明细数据失败: %v", err)
-	}
-	fmt.Printf("✓ 成功插入 %d 个订单明细\n", len(orderItems))
-
-	fmt.Println("✓ 所有测试数据插入完成！")
+		movements[i] = StockMovement{
+			ProductID:     product.ID,
+			Type:          StockMovementAdjustment,
+			Quantity:      product.Stock,
+			StockAfter:    product.Stock,
+			ReservedAfter: product.ReservedStock,
+			SalesAfter:    product.Sales,
+			Reason:        "测试数据期初库存",
+		}
+	}
+	if len(movements) > 0 {
+		if err := tx.CreateInBatches(movements, seedBatchSize).Error; err != nil {
+			return fmt.Errorf("插入库存流水数据失败: %v", err)
+		}
+	}
+
+	result.Categories = len(s.categories)
+	result.Users = len(s.users)
+	result.Addresses = len(addresses)
+	result.Products = len(products)
+	result.Orders = len(orders)
+	result.OrderItems = len(orderItems)
+	for i := 0; i < len(s.users) && i < 5; i++ {
+		result.Accounts = append(result.Accounts, s.users[i].Username)
+	}
 	return nil
 }
 
+// seedGenerator 基于固定种子的随机数生成器，相同的种子生成相同的序列
+type seedGenerator struct {
+	rnd *rand.Rand
+	now time.Time
+}
+
+// intn 返回 [0, n) 之间的随机数
+func (g *seedGenerator) intn(n int) int {
+	return g.rnd.IntN(n)
+}
+
+// between 返回 [min, max] 之间的随机数
+func (g *seedGenerator) between(min, max int) int {
+	return min + g.rnd.IntN(max-min+1)
+}
+
+// pick 随机选择一个元素
+func pick[T any](g *seedGenerator, items []T) T {
+	return items[g.intn(len(items))]
+}
+
+// weighted 按权重随机选择下标
+func (g *seedGenerator) weighted(weights []int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := g.intn(total)
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(weights) - 1
+}
+
+// ago 返回当前时间之前 [minDays, maxDays) 天内的随机时间点
+func (g *seedGenerator) ago(minDays, maxDays int) time.Time {
+	span := time.Duration(maxDays-minDays) * 24 * time.Hour
+	offset := time.Duration(minDays)*24*time.Hour + time.Duration(g.rnd.Int64N(int64(span)))
+	return g.now.Add(-offset).Truncate(time.Second)
+}
+
+// generateSeedSet 根据参数在内存中生成测试数据
+func generateSeedSet(opts SeedOptions, now time.Time) *seedSet {
+	g := &seedGenerator{
+		rnd: rand.New(rand.NewPCG(uint64(opts.Seed), 0x5eed)),
+		now: now,
+	}
+	s := &seedSet{}
+	leaves := s.generateCategories()
+	s.generateUsers(g, opts.Users, opts.Addresses)
+	s.generateProducts(g, opts.Products, leaves)
+	s.generateOrders(g, opts.Orders)
+	return s
+}
+
+// generateCategories 生成固定的两级分类树，返回可以挂商品的分类（叶子分类）下标
+func (s *seedSet) generateCategories() []int {
+	var leaves []int
+	for i, top := range seedCategoryTree {
+		parent := len(s.categories)
+		s.categories = append(s.categories, seedCategory{
+			Category: Category{Name: top.name, Sort: i + 1, Status: CategoryStatusEnabled},
+			parent:   -1,
+		})
+		if len(top.children) == 0 {
+			leaves = append(leaves, parent)
+			continue
+		}
+		for j, child := range top.children {
+			leaves = append(leaves, len(s.categories))
+			s.categories = append(s.categories, seedCategory{
+				Category: Category{Name: child, Sort: j + 1, Status: CategoryStatusEnabled},
+				parent:   parent,
+			})
+		}
+	}
+	return leaves
+}
+
+// generateUsers 生成顾客及其收货地址，用户名由姓名拼音组成，重名时追加序号
+func (s *seedSet) generateUsers(g *seedGenerator, count, maxAddresses int) {
+	usernames := make(map[string]int)
+	phones := make(map[string]bool)
+	for i := 0; i < count; i++ {
+		surname := pick(g, seedSurnames)
+		given := pick(g, seedGivenNames)
+		name := surname.hanzi + given.hanzi
+
+		username := surname.pinyin + given.pinyin
+		usernames[username]++
+		if n := usernames[username]; n > 1 {
+			username = fmt.Sprintf("%s%d", username, n)
+		}
+
+		phone := ""
+		for phone == "" || phones[phone] {
+			phone = fmt.Sprintf("%s%08d", pick(g, seedPhonePrefixes), g.intn(100000000))
+		}
+		phones[phone] = true
+
+		status := UserStatusNormal
+		if g.intn(100) < 3 {
+			status = UserStatusBanned
+		}
+
+		createdAt := g.ago(180, 720)
+		s.users = append(s.users, User{
+			Username:  username,
+			Phone:     phone,
+			Email:     username + "@example.com",
+			Nickname:  name,
+			Avatar:    "https://example.com/avatar/" + username + ".jpg",
+			Status:    status,
+			Role:      RoleCustomer,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+
+		addressCount := g.between(1, maxAddresses)
+		s.userAddrs = append(s.userAddrs, nil)
+		for j := 0; j < addressCount; j++ {
+			s.userAddrs[i] = append(s.userAddrs[i], len(s.addresses))
+			region := pick(g, seedRegions)
+			receiver, receiverPhone := name, phone
+			// 部分地址是给家人、同事收货
+			if j > 0 && g.intn(100) < 30 {
+				receiver = pick(g, seedSurnames).hanzi + pick(g, seedGivenNames).hanzi
+				receiverPhone = fmt.Sprintf("%s%08d", pick(g, seedPhonePrefixes), g.intn(100000000))
+			}
+			s.addresses = append(s.addresses, seedAddress{
+				Address: Address{
+					ReceiverName:  receiver,
+					ReceiverPhone: receiverPhone,
+					Province:      region.province,
+					City:          region.city,
+					District:      pick(g, region.districts),
+					Detail:        seedAddressDetail(g),
+					PostalCode:    fmt.Sprintf("%06d", region.postalCode+g.intn(100)),
+					IsDefault:     j == 0,
+					CreatedAt:     createdAt,
+					UpdatedAt:     createdAt,
+				},
+				user: i,
+			})
+		}
+	}
+}
+
+// seedAddressDetail 生成详细地址，例如"人民路88号阳光花园3栋2单元1502室"
+func seedAddressDetail(g *seedGenerator) string {
+	street := fmt.Sprintf("%s%d号", pick(g, seedStreets), g.between(1, 999))
+	if g.intn(100) < 20 {
+		return street
+	}
+	return fmt.Sprintf("%s%s%d栋%d单元%d%02d室", street, pick(g, seedCommunities),
+		g.between(1, 30), g.between(1, 6), g.between(1, 32), g.between(1, 4))
+}
+
+// generateProducts 生成商品，名称由品牌和品类组成，价格和库存在模板范围内随机
+func (s *seedSet) generateProducts(g *seedGenerator, count int, leaves []int) {
+	batch := g.now.Format("20060102")
+	for i := 0; i < count; i++ {
+		category := pick(g, leaves)
+		template := seedProductTemplates[s.categories[category].Name]
+		brand := pick(g, template.brands)
+		item := pick(g, template.items)
+
+		// 价格取整到 9 结尾，例如 2999.00
+		price := g.between(template.minPrice, template.maxPrice)/10*10 + 9
+		if price > template.maxPrice {
+			price -= 10
+		}
+
+		stock := g.between(50, 1000)
+		if g.intn(100) < 5 {
+			stock = 0
+		}
+		status := ProductStatusOnSale
+		if g.intn(100) < 10 {
+			status = ProductStatusOffSale
+		}
+
+		createdAt := g.ago(200, 400)
+		s.products = append(s.products, seedProduct{
+			Product: Product{
+				ProductNo:   fmt.Sprintf("PROD%s%06d", batch, i+1),
+				Name:        brand + " " + item,
+				Description: fmt.Sprintf("%s正品%s，%s", brand, item, pick(g, seedProductSlogans)),
+				Price:       Yuan(int64(price)),
+				Stock:       stock,
+				Image:       fmt.Sprintf("https://example.com/images/products/%d.jpg", i+1),
+				Status:      status,
+				Sort:        i + 1,
+				CreatedAt:   createdAt,
+				UpdatedAt:   createdAt,
+			},
+			category: category,
+		})
+	}
+}
+
+// 订单状态的分布权重，与 seedOrderStatuses 一一对应
+var (
+	seedOrderStatuses = []int8{OrderStatusPending, OrderStatusPaid, OrderStatusShipped, OrderStatusCompleted, OrderStatusCancelled}
+	seedStatusWeights = []int{10, 15, 15, 50, 10}
+)
+
+// generateOrders 生成订单，待支付订单的商品预占库存，已支付的商品扣减库存并增加销量
+// 下单时间随状态变化：待支付的订单在最近 20 分钟内，已完成和已取消的订单分布在最近半年
+func (s *seedSet) generateOrders(g *seedGenerator, count int) {
+	var onSale []int
+	for i, product := range s.products {
+		if product.Status == ProductStatusOnSale {
+			onSale = append(onSale, i)
+		}
+	}
+	if len(onSale) == 0 {
+		return
+	}
+
+	for i := 0; i < count; i++ {
+		status := seedOrderStatuses[g.weighted(seedStatusWeights)]
+		user := g.intn(len(s.users))
+		order := seedOrder{user: user, address: s.pickAddress(g, user)}
+
+		// 每个订单 1~4 种不同的商品，库存不足的商品跳过
+		itemCount := []int{1, 2, 3, 4}[g.weighted([]int{50, 30, 15, 5})]
+		chosen := make(map[int]bool)
+		for attempt := 0; attempt < itemCount*3 && len(order.items) < itemCount; attempt++ {
+			index := pick(g, onSale)
+			product := &s.products[index]
+			quantity := []int{1, 2, 3}[g.weighted([]int{70, 20, 10})]
+			if chosen[index] || product.Stock-product.ReservedStock < quantity {
+				continue
+			}
+			chosen[index] = true
+			switch status {
+			case OrderStatusPending:
+				product.ReservedStock += quantity
+			case OrderStatusPaid, OrderStatusShipped, OrderStatusCompleted:
+				product.Stock -= quantity
+				product.Sales += quantity
+			}
+			order.items = append(order.items, seedOrderItem{
+				OrderItem: OrderItem{
+					ProductName:  product.Name,
+					ProductImage: product.Image,
+					Price:        product.Price,
+					Quantity:     quantity,
+					Subtotal:     product.Price.Mul(quantity),
+				},
+				product: index,
+			})
+		}
+		if len(order.items) == 0 {
+			continue
+		}
+
+		total := Money{}
+		for _, item := range order.items {
+			total = total.Add(item.Subtotal)
+		}
+		// 部分订单使用优惠券，优惠金额不超过订单金额的五分之一
+		discount := Money{}
+		if g.intn(100) < 30 {
+			if coupon := Yuan(int64(pick(g, []int{5, 10, 20, 50, 100}))); coupon.Mul(5).Fen() <= total.Fen() {
+				discount = coupon
+			}
+		}
+
+		createdAt := g.orderTime(status)
+		order.Order = Order{
+			OrderNo:        fmt.Sprintf("ORD%s%08d", createdAt.Format("20060102"), len(s.orders)+1),
+			TotalAmount:    total,
+			DiscountAmount: discount,
+			PayAmount:      total.Sub(discount),
+			Status:         status,
+			Remark:         pick(g, seedRemarks),
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
+		}
+		if status == OrderStatusPaid || status == OrderStatusShipped || status == OrderStatusCompleted {
+			payTime := createdAt.Add(time.Duration(g.between(1, 30)) * time.Minute)
+			order.PayMethod = pick(g, []string{"支付宝", "微信支付", "银联"})
+			order.PayTime = &payTime
+			order.UpdatedAt = payTime
+		}
+		if status == OrderStatusShipped || status == OrderStatusCompleted {
+			shipTime := order.PayTime.Add(time.Duration(g.between(2, 48)) * time.Hour)
+			order.ShipTime = &shipTime
+			order.UpdatedAt = shipTime
+		}
+		if status == OrderStatusCompleted {
+			completeTime := order.ShipTime.Add(time.Duration(g.between(1, 7)) * 24 * time.Hour)
+			order.CompleteTime = &completeTime
+			order.UpdatedAt = completeTime
+		}
+		s.orders = append(s.orders, order)
+	}
+}
+
+// pickAddress 选择下单地址，大多数订单使用默认地址
+func (s *seedSet) pickAddress(g *seedGenerator, user int) int {
+	owned := s.userAddrs[user]
+	if len(owned) == 1 || g.intn(100) < 70 {
+		return owned[0] // 第一个地址是默认地址
+	}
+	return pick(g, owned)
+}
+
+// orderTime 按订单状态生成下单时间，保证支付、发货、完成时间都早于当前时间
+// 待支付的订单不超过默认的 orders.pending_ttl（30m），启动服务后不会马上被自动取消
+func (g *seedGenerator) orderTime(status int8) time.Time {
+	switch status {
+	case OrderStatusPending:
+		return g.now.Add(-time.Duration(g.between(1, 20)) * time.Minute).Truncate(time.Second)
+	case OrderStatusPaid:
+		return g.ago(1, 3)
+	case OrderStatusShipped:
+		return g.ago(3, 10)
+	default:
+		return g.ago(10, 180)
+	}
+}
+
+// runSeedCommand 执行 seed 命令，返回进程退出码
+// 用法: seed [-users N] [-addresses N] [-products N] [-orders N] [-seed N] [-reset]
+func runSeedCommand(db *gorm.DB, args []string) int {
+	var opts SeedOptions
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.IntVar(&opts.Users, "users", defaultSeedUsers, "用户数量")
+	flags.IntVar(&opts.Addresses, "addresses", defaultSeedAddresses, "每个用户最多的收货地址数量")
+	flags.IntVar(&opts.Products, "products", defaultSeedProducts, "商品数量")
+	flags.IntVar(&opts.Orders, "orders", defaultSeedOrders, "订单数量")
+	flags.Int64Var(&opts.Seed, "seed", 0, "随机数种子，相同的种子生成相同的数据")
+	flags.BoolVar(&opts.Reset, "reset", false, "先清空已有的业务数据（保留管理员和运营账号）再生成")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	result, err := seedData(db, opts)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		if errors.Is(err, ErrInvalidSeedOptions) {
+			return exitUsage
+		}
+		return exitFailure
+	}
+	if result.Skipped {
+		fmt.Println("✓ 数据库中已有数据，跳过插入（使用 -reset 清空后重新生成）")
+		return exitOK
+	}
+	fmt.Printf("✓ 成功插入 %d 个用户、%d 个地址、%d 个分类、%d 个商品、%d 个订单、%d 个订单明细（seed=%d）\n",
+		result.Users, result.Addresses, result.Categories, result.Products, result.Orders, result.OrderItems, result.Seed)
+	fmt.Printf("✓ 测试用户密码均为 %s，例如: %s\n", seedUserPassword, strings.Join(result.Accounts, "、"))
+	return exitOK
+}
@@ -0,0 +1,113 @@
+package main
+
+// 测试数据生成使用的词库
+
+// seedName 汉字及其拼音，拼音用于生成用户名
+type seedName struct {
+	hanzi  string
+	pinyin string
+}
+
+// seedSurnames 常见姓氏
+var seedSurnames = []seedName{
+	{"王", "wang"}, {"李", "li"}, {"张", "zhang"}, {"刘", "liu"}, {"陈", "chen"},
+	{"杨", "yang"}, {"黄", "huang"}, {"赵", "zhao"}, {"吴", "wu"}, {"周", "zhou"},
+	{"徐", "xu"}, {"孙", "sun"}, {"马", "ma"}, {"朱", "zhu"}, {"胡", "hu"},
+	{"郭", "guo"}, {"何", "he"}, {"林", "lin"}, {"高", "gao"}, {"罗", "luo"},
+	{"郑", "zheng"}, {"梁", "liang"}, {"谢", "xie"}, {"宋", "song"}, {"唐", "tang"},
+	{"韩", "han"}, {"冯", "feng"}, {"邓", "deng"}, {"曹", "cao"}, {"彭", "peng"},
+}
+
+// seedGivenNames 常见名字
+var seedGivenNames = []seedName{
+	{"伟", "wei"}, {"芳", "fang"}, {"娜", "na"}, {"敏", "min"}, {"静", "jing"},
+	{"丽", "li"}, {"强", "qiang"}, {"磊", "lei"}, {"军", "jun"}, {"洋", "yang"},
+	{"勇", "yong"}, {"艳", "yan"}, {"杰", "jie"}, {"涛", "tao"}, {"明", "ming"},
+	{"超", "chao"}, {"霞", "xia"}, {"平", "ping"}, {"刚", "gang"}, {"晨", "chen"},
+	{"秀英", "xiuying"}, {"建华", "jianhua"}, {"志强", "zhiqiang"}, {"子轩", "zixuan"},
+	{"雨涵", "yuhan"}, {"浩然", "haoran"}, {"欣怡", "xinyi"}, {"梓涵", "zihan"},
+	{"宇航", "yuhang"}, {"思远", "siyuan"}, {"嘉怡", "jiayi"}, {"博文", "bowen"},
+	{"婷婷", "tingting"}, {"佳琪", "jiaqi"}, {"俊杰", "junjie"}, {"晓燕", "xiaoyan"},
+}
+
+// seedPhonePrefixes 手机号段
+var seedPhonePrefixes = []string{
+	"130", "131", "132", "133", "135", "136", "137", "138", "139",
+	"150", "151", "152", "153", "155", "156", "157", "158", "159",
+	"177", "180", "181", "182", "183", "185", "186", "187", "188", "189", "199",
+}
+
+// seedRegion 省、市、区及邮政编码前缀
+type seedRegion struct {
+	province   string
+	city       string
+	districts  []string
+	postalCode int
+}
+
+var seedRegions = []seedRegion{
+	{"北京市", "北京市", []string{"海淀区", "朝阳区", "东城区", "西城区", "丰台区"}, 100000},
+	{"上海市", "上海市", []string{"浦东新区", "徐汇区", "静安区", "黄浦区", "闵行区"}, 200000},
+	{"天津市", "天津市", []string{"和平区", "南开区", "河西区", "滨海新区"}, 300000},
+	{"重庆市", "重庆市", []string{"渝中区", "江北区", "南岸区", "渝北区"}, 400000},
+	{"广东省", "广州市", []string{"天河区", "越秀区", "海珠区", "番禺区"}, 510000},
+	{"广东省", "深圳市", []string{"南山区", "福田区", "罗湖区", "宝安区", "龙岗区"}, 518000},
+	{"浙江省", "杭州市", []string{"西湖区", "上城区", "拱墅区", "滨江区", "余杭区"}, 310000},
+	{"江苏省", "南京市", []string{"玄武区", "秦淮区", "鼓楼区", "建邺区"}, 210000},
+	{"江苏省", "苏州市", []string{"姑苏区", "吴中区", "虎丘区", "相城区"}, 215000},
+	{"四川省", "成都市", []string{"锦江区", "青羊区", "武侯区", "成华区"}, 610000},
+	{"湖北省", "武汉市", []string{"江汉区", "武昌区", "洪山区", "汉阳区"}, 430000},
+	{"陕西省", "西安市", []string{"雁塔区", "碑林区", "未央区", "长安区"}, 710000},
+	{"山东省", "青岛市", []string{"市南区", "市北区", "崂山区", "黄岛区"}, 266000},
+	{"福建省", "厦门市", []string{"思明区", "湖里区", "集美区"}, 361000},
+	{"湖南省", "长沙市", []string{"岳麓区", "芙蓉区", "天心区", "雨花区"}, 410000},
+}
+
+var seedStreets = []string{
+	"中山路", "人民路", "解放路", "建设路", "和平路", "长江路",
+	"文化路", "新华路", "科技路", "学府路", "滨江大道", "世纪大道",
+}
+
+var seedCommunities = []string{
+	"阳光花园", "锦绣家园", "翠苑", "金色家园", "绿城花园", "幸福小区", "书香门第", "滨江花园",
+}
+
+var seedRemarks = []string{"", "", "", "", "请尽快发货", "工作日配送", "周末配送", "放快递柜", "送货前电话联系"}
+
+var seedProductSlogans = []string{"全国联保", "七天无理由退换", "官方旗舰店发货", "限时特惠", "品质保障"}
+
+// seedCategoryTree 分类树，没有子分类的顶级分类直接挂商品
+var seedCategoryTree = []struct {
+	name     string
+	children []string
+}{
+	{"数码电子", []string{"手机", "耳机", "电脑", "平板"}},
+	{"家用电器", []string{"厨房电器", "生活电器"}},
+	{"服饰鞋包", []string{"男装", "女装", "运动鞋"}},
+	{"食品饮料", []string{"零食", "茶叶", "咖啡"}},
+	{"配件", nil},
+}
+
+// seedProductTemplate 叶子分类下的商品模板，价格单位为元
+type seedProductTemplate struct {
+	brands   []string
+	items    []string
+	minPrice int
+	maxPrice int
+}
+
+var seedProductTemplates = map[string]seedProductTemplate{
+	"手机":   {[]string{"华为", "小米", "OPPO", "vivo", "荣耀", "苹果"}, []string{"智能手机 8GB+256GB", "智能手机 12GB+256GB", "智能手机 12GB+512GB", "折叠屏手机 16GB+512GB"}, 999, 12999},
+	"耳机":   {[]string{"华为", "小米", "索尼", "苹果", "漫步者"}, []string{"真无线降噪耳机", "头戴式降噪耳机", "运动蓝牙耳机", "有线入耳式耳机"}, 99, 2999},
+	"电脑":   {[]string{"联想", "华为", "苹果", "戴尔", "惠普", "华硕"}, []string{"轻薄笔记本 14英寸", "游戏本 16英寸", "商务笔记本 13英寸", "台式一体机 27英寸"}, 3999, 19999},
+	"平板":   {[]string{"苹果", "华为", "小米", "荣耀"}, []string{"平板电脑 11英寸", "平板电脑 12.4英寸", "学习平板 10.1英寸"}, 1299, 8999},
+	"厨房电器": {[]string{"美的", "九阳", "苏泊尔", "格兰仕"}, []string{"电饭煲 4L", "破壁料理机", "空气炸锅 5L", "微波炉 20L", "电热水壶 1.7L"}, 99, 1299},
+	"生活电器": {[]string{"戴森", "美的", "飞利浦", "小米"}, []string{"吹风机", "扫地机器人", "空气净化器", "电动牙刷", "加湿器"}, 99, 4999},
+	"男装":   {[]string{"优衣库", "海澜之家", "李宁", "太平鸟"}, []string{"纯棉T恤", "休闲衬衫", "直筒牛仔裤", "连帽羽绒服", "夹克"}, 59, 999},
+	"女装":   {[]string{"优衣库", "太平鸟", "欧时力", "ONLY"}, []string{"连衣裙", "针织开衫", "阔腿裤", "风衣", "短款羽绒服"}, 79, 1299},
+	"运动鞋":  {[]string{"耐克", "阿迪达斯", "李宁", "安踏", "特步"}, []string{"跑步鞋", "篮球鞋", "板鞋", "徒步鞋"}, 199, 1499},
+	"零食":   {[]string{"三只松鼠", "良品铺子", "百草味", "来伊份"}, []string{"坚果礼盒 1.5kg", "每日坚果 750g", "牛肉干 200g", "芒果干 500g"}, 19, 199},
+	"茶叶":   {[]string{"西湖", "大益", "张一元", "八马"}, []string{"龙井茶 250g", "普洱茶饼 357g", "茉莉花茶 200g", "铁观音 250g"}, 49, 899},
+	"咖啡":   {[]string{"雀巢", "三顿半", "隅田川", "星巴克"}, []string{"速溶咖啡 30杯", "挂耳咖啡 20包", "咖啡豆 500g", "冷萃咖啡液 12杯"}, 29, 299},
+	"配件":   {[]string{"绿联", "安克", "倍思", "公牛"}, []string{"手机保护壳", "65W 快充充电器", "数据线 1m", "充电宝 20000mAh", "钢化膜 2片装"}, 9, 399},
+}
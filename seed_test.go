@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateSeedSetDeterministic(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	opts, err := SeedOptions{Users: 30, Products: 50, Orders: 300, Seed: 42}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := generateSeedSet(opts, now)
+	second := generateSeedSet(opts, now)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("相同的种子生成了不同的数据")
+	}
+
+	opts.Seed = 43
+	if other := generateSeedSet(opts, now); reflect.DeepEqual(first.users, other.users) {
+		t.Error("不同的种子生成了相同的用户")
+	}
+}
+
+func TestGenerateSeedSetConsistency(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	opts, err := SeedOptions{Users: 50, Addresses: 3, Products: 30, Orders: 500, Seed: 1}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := generateSeedSet(opts, now)
+
+	if len(set.users) != 50 || len(set.products) != 30 || len(set.orders) == 0 {
+		t.Fatalf("生成 %d 个用户、%d 个商品、%d 个订单", len(set.users), len(set.products), len(set.orders))
+	}
+
+	usernames := make(map[string]bool)
+	phones := make(map[string]bool)
+	for _, user := range set.users {
+		if usernames[user.Username] || phones[user.Phone] {
+			t.Errorf("用户名或手机号重复: %s %s", user.Username, user.Phone)
+		}
+		usernames[user.Username] = true
+		phones[user.Phone] = true
+		if len(user.Phone) != 11 {
+			t.Errorf("手机号 %q 不是 11 位", user.Phone)
+		}
+	}
+
+	// 每个用户有且只有一个默认地址
+	defaults := make(map[int]int)
+	for _, address := range set.addresses {
+		if address.IsDefault {
+			defaults[address.user]++
+		}
+	}
+	for i := range set.users {
+		if defaults[i] != 1 {
+			t.Errorf("用户 %d 有 %d 个默认地址", i, defaults[i])
+		}
+	}
+
+	// 销量与已支付订单的购买数量一致，预占数量与待支付订单的购买数量一致
+	sold := make(map[int]int)
+	reserved := make(map[int]int)
+	statuses := make(map[int8]int)
+	for _, order := range set.orders {
+		statuses[order.Status]++
+		if set.addresses[order.address].user != order.user {
+			t.Errorf("订单 %s 使用了其他用户的地址", order.OrderNo)
+		}
+
+		total := Money{}
+		for _, item := range order.items {
+			total = total.Add(item.Subtotal)
+			switch order.Status {
+			case OrderStatusPending:
+				reserved[item.product] += item.Quantity
+			case OrderStatusPaid, OrderStatusShipped, OrderStatusCompleted:
+				sold[item.product] += item.Quantity
+			}
+		}
+		if total != order.TotalAmount || order.PayAmount != total.Sub(order.DiscountAmount) || order.PayAmount.Fen() <= 0 {
+			t.Errorf("订单 %s 金额不一致: 合计 %s，订单 %s，优惠 %s，实付 %s",
+				order.OrderNo, total, order.TotalAmount, order.DiscountAmount, order.PayAmount)
+		}
+
+		// 各状态的时间字段按顺序出现且不晚于当前时间
+		paid := order.Status == OrderStatusPaid || order.Status == OrderStatusShipped || order.Status == OrderStatusCompleted
+		if paid != (order.PayTime != nil) {
+			t.Errorf("订单 %s 状态 %d 的支付时间 = %v", order.OrderNo, order.Status, order.PayTime)
+		}
+		last := order.CreatedAt
+		for _, ts := range []*time.Time{order.PayTime, order.ShipTime, order.CompleteTime} {
+			if ts == nil {
+				continue
+			}
+			if ts.Before(last) || ts.After(now) {
+				t.Errorf("订单 %s 的时间顺序错误", order.OrderNo)
+			}
+			last = *ts
+		}
+	}
+	for i, product := range set.products {
+		if product.Sales != sold[i] || product.ReservedStock != reserved[i] || product.Stock < product.ReservedStock {
+			t.Errorf("商品 %s 销量 = %d，订单中售出 %d，预占 %d，待支付 %d，库存 %d",
+				product.Name, product.Sales, sold[i], product.ReservedStock, reserved[i], product.Stock)
+		}
+	}
+	for _, status := range seedOrderStatuses {
+		if statuses[status] == 0 {
+			t.Errorf("没有状态为 %s 的订单", orderStatusText[status])
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "context"
+
+// Server HTTP 服务，持有 handler 需要的所有依赖
+// handler 通过 Server 上的数据访问接口读写数据，不直接依赖数据库，测试时可以替换为内存实现
+type Server struct {
+	users      UserRepository
+	products   ProductRepository
+	orders     OrderRepository
+	addresses  AddressRepository
+	categories CategoryRepository
+	reports    ReportRepository
+	auth       AuthConfig
+	seed       func(SeedOptions) (*SeedResult, error) // 生成测试数据，POST /seed 使用
+	ready      func(context.Context) ReadinessReport  // 就绪检查，GET /readyz 使用
+	metrics    *Metrics                               // Prometheus 指标，为 nil 时不记录指标也不注册 /metrics
+}
+
+// NewServer 创建 HTTP 服务
+func NewServer(repos Repositories, auth AuthConfig, seed func(SeedOptions) (*SeedResult, error),
+	ready func(context.Context) ReadinessReport, metrics *Metrics) *Server {
+	return &Server{
+		users:      repos.Users,
+		products:   repos.Products,
+		orders:     repos.Orders,
+		addresses:  repos.Addresses,
+		categories: repos.Categories,
+		reports:    repos.Reports,
+		auth:       auth,
+		seed:       seed,
+		ready:      ready,
+		metrics:    metrics,
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 测试夹具：seedFixtures 插入的数据在全新数据库中的 ID 是固定的
+const (
+	fixtureZhangsanID = 1 // 顾客，地址 1（默认）、2，订单 1（已支付）、2（待支付）
+	fixtureLisiID     = 2 // 顾客，地址 3（默认），订单 3（已发货）
+	fixtureWangwuID   = 3 // 顾客，没有地址和订单
+	fixtureAdminID    = 4
+	fixtureOperatorID = 5
+
+	fixtureCategoryDigital   = 1 // 数码电子，子分类 3、4、5
+	fixtureCategoryAccessory = 2 // 配件
+	fixtureCategoryPhone     = 3 // 手机
+	fixtureCategoryLaptop    = 5 // 电脑
+
+	fixtureIPhoneID  = 1 // 7999.00，库存 100，分类 3
+	fixtureAirPodsID = 2 // 1899.00，库存 200，分类 4
+	fixtureMacBookID = 3 // 14999.00，库存 50，分类 5
+	fixtureCaseID    = 4 // 99.00，库存 500，分类 2
+
+	fixturePaidOrderID    = 1 // 张三，已支付，iPhone × 1
+	fixturePendingOrderID = 2 // 张三，待支付，AirPods × 1 + 保护壳 × 1
+	fixtureShippedOrderID = 3 // 李四，已发货，MacBook × 1
+)
+
+// testServer 集成测试使用的 HTTP 服务
+// 每个测试使用独立的 SQLite 内存数据库，执行版本化迁移并插入测试数据，互不影响
+type testServer struct {
+	t       *testing.T
+	db      *gorm.DB
+	router  *gin.Engine
+	metrics *Metrics
+}
+
+// apiResponse 解析后的统一响应
+type apiResponse struct {
+	Status  int
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// newTestServer 创建插入了测试夹具的服务：seedFixtures 的数据，加上管理员 admin 和运营 operator
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	ts := newEmptyTestServer(t)
+	if err := seedFixtures(ts.db); err != nil {
+		t.Fatalf("插入测试数据失败: %v", err)
+	}
+	createTestUser(t, ts.db, "admin", RoleAdmin)
+	createTestUser(t, ts.db, "operator", RoleOperator)
+	return ts
+}
+
+// newEmptyTestServer 创建只执行了迁移、没有任何数据的服务
+func newEmptyTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := connectDB(DBConfig{Driver: DriverSQLite, Database: sqliteMemory})
+	if err != nil {
+		t.Fatalf("连接测试数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := migrateUp(db, 0); err != nil {
+		t.Fatalf("执行迁移失败: %v", err)
+	}
+
+	auth := AuthConfig{
+		Secret:     []byte("integration-test-secret-0123456789"),
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	}
+	metrics := NewMetrics()
+	if err := metrics.InstrumentDB(db, sqliteMemory); err != nil {
+		t.Fatalf("注册数据库指标失败: %v", err)
+	}
+	if err := db.Use(gormTracing{}); err != nil {
+		t.Fatalf("注册数据库链路追踪失败: %v", err)
+	}
+	server := NewServer(NewGormRepositories(db), auth, func(opts SeedOptions) (*SeedResult, error) {
+		return seedData(db, opts)
+	}, func(ctx context.Context) ReadinessReport {
+		return checkReadiness(ctx, db, false)
+	}, metrics)
+	return &testServer{t: t, db: db, router: SetupRoutes(server, defaultConfig().Server), metrics: metrics}
+}
+
+// createTestUser 直接写入数据库创建指定角色的用户，密码与测试数据相同
+func createTestUser(t *testing.T, db *gorm.DB, username, role string) {
+	t.Helper()
+	passwordHash, err := hashPassword(seedUserPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := User{
+		Username: username,
+		Phone:    "139" + username,
+		Email:    username + "@example.com",
+		Password: passwordHash,
+		Nickname: username,
+		Status:   UserStatusNormal,
+		Role:     role,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("创建用户 %s 失败: %v", username, err)
+	}
+}
+
+// do 发送请求，body 为 nil 时不带请求体，token 为空时不带认证头
+func (ts *testServer) do(method, path, token string, body interface{}) apiResponse {
+	ts.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			ts.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+
+	resp := apiResponse{Status: w.Code}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		ts.t.Fatalf("%s %s: 响应不是合法的 JSON: %v\n%s", method, path, err, w.Body.String())
+	}
+	return resp
+}
+
+// expect 发送请求并校验 HTTP 状态码，以及响应体中的 code 与状态码一致
+func (ts *testServer) expect(method, path, token string, body interface{}, status int) apiResponse {
+	ts.t.Helper()
+	resp := ts.do(method, path, token, body)
+	if resp.Status != status {
+		ts.t.Fatalf("%s %s: 状态码 = %d，期望 %d（%s）", method, path, resp.Status, status, resp.Message)
+	}
+	if resp.Code != status {
+		ts.t.Fatalf("%s %s: 响应 code = %d，与状态码 %d 不一致", method, path, resp.Code, status)
+	}
+	if resp.Message == "" {
+		ts.t.Fatalf("%s %s: 响应缺少 message", method, path)
+	}
+	return resp
+}
+
+// login 登录并返回访问令牌
+func (ts *testServer) login(account string) string {
+	ts.t.Helper()
+	resp := ts.expect(http.MethodPost, "/auth/login", "", LoginRequest{Account: account, Password: seedUserPassword}, http.StatusOK)
+	var tokens TokenResponse
+	resp.decode(ts.t, &tokens)
+	return tokens.AccessToken
+}
+
+// decode 将 data 解析到 v
+func (r apiResponse) decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if len(r.Data) == 0 {
+		t.Fatalf("响应缺少 data（%s）", r.Message)
+	}
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		t.Fatalf("解析 data 失败: %v\n%s", err, r.Data)
+	}
+}
+
+// pageResult 分页响应，items 按具体类型解析
+type pageResult[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func TestHealth(t *testing.T) {
+	ts := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d，期望 200", w.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["status"] != "ok" {
+		t.Fatalf("响应 = %s", w.Body.String())
+	}
+}
+
+func TestProbes(t *testing.T) {
+	ts := newEmptyTestServer(t)
+
+	probe := func(path string, status int) ReadinessReport {
+		t.Helper()
+		w := httptest.NewRecorder()
+		ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != status {
+			t.Fatalf("%s: 状态码 = %d，期望 %d\n%s", path, w.Code, status, w.Body.String())
+		}
+		var report ReadinessReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	probe("/livez", http.StatusOK)
+	report := probe("/readyz", http.StatusOK)
+	if report.Database != HealthStatusOK || report.Migrations == nil || !report.Migrations.UpToDate() {
+		t.Errorf("就绪检查 = %+v", report)
+	}
+
+	// 有未执行的迁移
+	if _, err := migrateDown(ts.db, 1); err != nil {
+		t.Fatal(err)
+	}
+	report = probe("/readyz", http.StatusServiceUnavailable)
+	if report.Migrations == nil || report.Migrations.Pending != 1 {
+		t.Errorf("就绪检查 = %+v", report)
+	}
+
+	// 数据库不可用时存活探针不受影响
+	sqlDB, err := ts.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+	if report = probe("/readyz", http.StatusServiceUnavailable); report.Database == HealthStatusOK {
+		t.Errorf("就绪检查 = %+v", report)
+	}
+	probe("/livez", http.StatusOK)
+}
+
+// TestReadinessMigrationModes AutoMigrate 建表时跳过迁移检查；版本化迁移的检查只读，不创建迁移历史表
+func TestReadinessMigrationModes(t *testing.T) {
+	db, err := connectDB(DBConfig{Driver: DriverSQLite, Database: sqliteMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeDB(db) })
+	if err := autoMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	report := checkReadiness(ctx, db, true)
+	if report.Status != HealthStatusOK || !report.MigrationsSkipped || report.Migrations != nil {
+		t.Errorf("auto_migrate 模式的就绪检查 = %+v", report)
+	}
+
+	report = checkReadiness(ctx, db, false)
+	if report.Status != HealthStatusUnavailable || report.Migrations == nil ||
+		report.Migrations.Pending != report.Migrations.Total || report.Migrations.Total == 0 {
+		t.Errorf("没有迁移历史时的就绪检查 = %+v", report)
+	}
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		t.Error("就绪检查不应创建迁移历史表")
+	}
+}
+
+func TestCORS(t *testing.T) {
+	router := SetupRoutes(NewServer(Repositories{}, AuthConfig{}, nil, nil, nil),
+		ServerConfig{CORSOrigins: []string{"https://shop.example.com"}})
+
+	cases := []struct {
+		method, origin, allowed string
+		status                  int
+	}{
+		{http.MethodGet, "https://shop.example.com", "https://shop.example.com", http.StatusOK},
+		{http.MethodOptions, "https://shop.example.com", "https://shop.example.com", http.StatusNoContent},
+		{http.MethodGet, "https://evil.example.com", "", http.StatusOK},
+		{http.MethodGet, "", "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/health", nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tc.status || w.Header().Get("Access-Control-Allow-Origin") != tc.allowed {
+			t.Errorf("%s %q: 状态码 = %d，Access-Control-Allow-Origin = %q，期望 %d %q", tc.method, tc.origin,
+				w.Code, w.Header().Get("Access-Control-Allow-Origin"), tc.status, tc.allowed)
+		}
+	}
+
+	// 默认配置允许所有来源
+	w := httptest.NewRecorder()
+	newTestServer(t).router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("默认 Access-Control-Allow-Origin = %q", got)
+	}
+}
+
+func TestProtectedRoutesRequireLogin(t *testing.T) {
+	ts := newTestServer(t)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/auth/me"},
+		{http.MethodPost, "/seed"},
+		{http.MethodGet, "/users"},
+		{http.MethodGet, "/users/1/orders"},
+		{http.MethodGet, "/users/1/orders/products"},
+		{http.MethodPut, "/users/1/role"},
+		{http.MethodGet, "/users/1/addresses"},
+		{http.MethodPost, "/users/1/addresses"},
+		{http.MethodPut, "/users/1/addresses/1"},
+		{http.MethodDelete, "/users/1/addresses/1"},
+		{http.MethodPost, "/users/1/addresses/1/default"},
+		{http.MethodGet, "/products/1/orders"},
+		{http.MethodGet, "/products/1/stats"},
+		{http.MethodPost, "/categories"},
+		{http.MethodPut, "/categories/1"},
+		{http.MethodDelete, "/categories/1"},
+		{http.MethodGet, "/orders"},
+		{http.MethodGet, "/orders/1"},
+		{http.MethodGet, "/orders/1/products"},
+		{http.MethodPost, "/orders"},
+		{http.MethodPost, "/orders/1/pay"},
+		{http.MethodPost, "/orders/1/ship"},
+		{http.MethodPost, "/orders/1/complete"},
+		{http.MethodPost, "/orders/1/cancel"},
+	}
+	for _, route := range routes {
+		resp := ts.expect(route.method, route.path, "", nil, http.StatusUnauthorized)
+		if resp.Message != "未登录" {
+			t.Errorf("%s %s: message = %q", route.method, route.path, resp.Message)
+		}
+		ts.expect(route.method, route.path, "not-a-jwt", nil, http.StatusUnauthorized)
+	}
+}
+
+func TestInvalidIDReturns400(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.login("admin")
+
+	routes := []struct{ method, path, message string }{
+		{http.MethodGet, "/users/abc/orders", "无效的用户ID"},
+		{http.MethodGet, "/users/abc/orders/products", "无效的用户ID"},
+		{http.MethodPut, "/users/abc/role", "无效的用户ID"},
+		{http.MethodGet, "/users/abc/addresses", "无效的用户ID"},
+		{http.MethodPost, "/users/abc/addresses", "无效的用户ID"},
+		{http.MethodPut, "/users/abc/addresses/1", "无效的用户ID"},
+		{http.MethodPut, "/users/1/addresses/abc", "无效的地址ID"},
+		{http.MethodDelete, "/users/1/addresses/abc", "无效的地址ID"},
+		{http.MethodPost, "/users/1/addresses/abc/default", "无效的地址ID"},
+		{http.MethodGet, "/products/abc", "无效的商品ID"},
+		{http.MethodGet, "/products/abc/orders", "无效的商品ID"},
+		{http.MethodGet, "/products/abc/stats", "无效的商品ID"},
+		{http.MethodGet, "/categories/abc", "无效的分类ID"},
+		{http.MethodGet, "/categories/abc/products", "无效的分类ID"},
+		{http.MethodPut, "/categories/abc", "无效的分类ID"},
+		{http.MethodDelete, "/categories/abc", "无效的分类ID"},
+		{http.MethodGet, "/orders/abc", "无效的订单ID"},
+		{http.MethodGet, "/orders/abc/products", "无效的订单ID"},
+		{http.MethodPost, "/orders/abc/pay", "无效的订单ID"},
+		{http.MethodPost, "/orders/abc/ship", "无效的订单ID"},
+		{http.MethodPost, "/orders/abc/complete", "无效的订单ID"},
+		{http.MethodPost, "/orders/abc/cancel", "无效的订单ID"},
+		{http.MethodGet, "/orders/-1", "无效的订单ID"},
+	}
+	for _, route := range routes {
+		resp := ts.expect(route.method, route.path, admin, nil, http.StatusBadRequest)
+		if resp.Message != route.message {
+			t.Errorf("%s %s: message = %q，期望 %q", route.method, route.path, resp.Message, route.message)
+		}
+	}
+}
+
+func TestMissingResourceReturns404(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.login("admin")
+
+	address := AddressRequest{
+		ReceiverName:  "测试",
+		ReceiverPhone: "13800000000",
+		Province:      "浙江省",
+		City:          "杭州市",
+		District:      "西湖区",
+		Detail:        "文三路 1 号",
+	}
+	routes := []struct {
+		method, path string
+		body         interface{}
+		message      string
+	}{
+		{http.MethodGet, "/users/999/orders", nil, "用户不存在"},
+		{http.MethodGet, "/users/999/orders/products", nil, "用户不存在"},
+		{http.MethodPut, "/users/999/role", UpdateUserRoleRequest{Role: RoleOperator}, "用户不存在"},
+		{http.MethodGet, "/users/999/addresses", nil, "用户不存在"},
+		{http.MethodPost, "/users/999/addresses", address, "用户不存在"},
+		{http.MethodPut, "/users/1/addresses/999", address, "收货地址不存在"},
+		{http.MethodDelete, "/users/1/addresses/999", nil, "收货地址不存在"},
+		{http.MethodPost, "/users/1/addresses/999/default", nil, "收货地址不存在"},
+		{http.MethodGet, "/products/999", nil, "商品不存在"},
+		{http.MethodGet, "/products/999/orders", nil, "商品不存在"},
+		{http.MethodGet, "/products/999/stats", nil, "商品不存在"},
+		{http.MethodGet, "/categories/999", nil, "分类不存在"},
+		{http.MethodGet, "/categories/999/products", nil, "分类不存在"},
+		{http.MethodPut, "/categories/999", CategoryRequest{Name: "不存在"}, "分类不存在"},
+		{http.MethodDelete, "/categories/999", nil, "分类不存在"},
+		{http.MethodGet, "/orders/999", nil, "订单不存在"},
+		{http.MethodGet, "/orders/999/products", nil, "订单不存在"},
+		{http.MethodPost, "/orders/999/pay", nil, "订单不存在"},
+		{http.MethodPost, "/orders/999/ship", nil, "订单不存在"},
+		{http.MethodPost, "/orders/999/complete", nil, "订单不存在"},
+		{http.MethodPost, "/orders/999/cancel", nil, "订单不存在"},
+	}
+	for _, route := range routes {
+		resp := ts.expect(route.method, route.path, admin, route.body, http.StatusNotFound)
+		if resp.Message != route.message {
+			t.Errorf("%s %s: message = %q，期望 %q", route.method, route.path, resp.Message, route.message)
+		}
+	}
+}
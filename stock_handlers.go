@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stockMovementTypes 库存流水的所有类型，用于校验 type 参数
+var stockMovementTypes = []string{
+	StockMovementOrderPlaced, StockMovementOrderPaid, StockMovementOrderCancelled,
+	StockMovementAdjustment, StockMovementRestock, StockMovementReturn,
+}
+
+var stockMovementListSpec = listSpec{
+	SortFields: map[string]sortField{
+		"id":         {Column: "id", Kind: sortKindInt},
+		"created_at": {Column: "created_at", Kind: sortKindTime},
+	},
+	DefaultSort: "id",
+	DefaultDesc: true,
+}
+
+// parseStockMovementTypes 解析逗号分隔的 type 参数，例如 type=restock,return
+func parseStockMovementTypes(c *gin.Context) ([]string, error) {
+	value := c.Query("type")
+	if value == "" {
+		return nil, nil
+	}
+	var types []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if !contains(stockMovementTypes, item) {
+			return nil, fmt.Errorf("%w: type 必须是 %s 之一", ErrInvalidListParams, strings.Join(stockMovementTypes, "、"))
+		}
+		types = append(types, item)
+	}
+	return types, nil
+}
+
+// GetProductStockHistory 分页查询商品的库存流水，默认按时间倒序
+// GET /products/:id/stock-history?type=restock,adjustment&order_id=1&start_date=2026-10-01&end_date=2026-10-31
+func (s *Server) GetProductStockHistory(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的商品ID",
+		})
+		return
+	}
+
+	params, err := parseListParams(c, stockMovementListSpec)
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+	var filter StockMovementFilter
+	filter.Types, err = parseStockMovementTypes(c)
+	if err == nil {
+		filter.OrderID, err = parseUintParam(c, "order_id")
+	}
+	if err == nil {
+		filter.Created, err = parseTimeRange(c)
+	}
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	result, err := s.products.StockHistory(c.Request.Context(), uint(productID), filter, params)
+	if errors.Is(err, ErrProductNotFound) {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		respondListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "查询成功",
+		Data:    result,
+	})
+}
+
+// AdjustProductStock 人工调整商品库存，写入一条库存流水并返回
+// POST /products/:id/stock-adjustments
+func (s *Server) AdjustProductStock(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "无效的商品ID",
+		})
+		return
+	}
+
+	var req StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误: " + err.Error(),
+		})
+		return
+	}
+
+	movement, err := s.products.AdjustStock(c.Request.Context(), uint(productID), req, currentUser(c).ID)
+	if err != nil {
+		status := orderErrorStatus(err)
+		c.JSON(status, Response{
+			Code:    status,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    201,
+		Message: "库存已调整",
+		Data:    movement,
+	})
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stockHistory 查询商品的全部库存流水，按 id 正序返回
+func (ts *testServer) stockHistory(t *testing.T, productID uint, query string) []StockMovement {
+	t.Helper()
+	var page PageResult
+	resp := ts.expect(http.MethodGet, fmt.Sprintf("/products/%d/stock-history?sort=id&order=asc&%s", productID, query),
+		ts.login("admin"), nil, http.StatusOK)
+	resp.decode(t, &page)
+	var movements []StockMovement
+	items, _ := json.Marshal(page.Items)
+	if err := json.Unmarshal(items, &movements); err != nil {
+		t.Fatal(err)
+	}
+	return movements
+}
+
+func TestStockMovementsForOrder(t *testing.T) {
+	ts := newTestServer(t)
+	zhangsan := ts.login("zhangsan")
+
+	req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureAirPodsID, Quantity: 2}}}
+	var order Order
+	ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated).decode(t, &order)
+	ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/pay", order.ID), zhangsan, nil, http.StatusOK)
+	ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/cancel", order.ID), zhangsan, nil, http.StatusOK)
+
+	movements := ts.stockHistory(t, fixtureAirPodsID, "")
+	if len(movements) != 3 {
+		t.Fatalf("库存流水 %d 条，期望 3 条", len(movements))
+	}
+	want := []StockMovement{
+		{Type: StockMovementOrderPlaced, Quantity: 2, StockBefore: 200, StockAfter: 200, ReservedAfter: 2},
+		{Type: StockMovementOrderPaid, Quantity: 2, StockBefore: 200, StockAfter: 198, ReservedBefore: 2, SalesAfter: 2},
+		{Type: StockMovementOrderCancelled, Quantity: 2, StockBefore: 198, StockAfter: 200, SalesBefore: 2},
+	}
+	for i, movement := range movements {
+		if movement.OrderID == nil || *movement.OrderID != order.ID ||
+			movement.OperatorID == nil || *movement.OperatorID != fixtureZhangsanID {
+			t.Errorf("第 %d 条流水的订单或操作人不正确: %+v", i+1, movement)
+		}
+		w := want[i]
+		if movement.Type != w.Type || movement.Quantity != w.Quantity ||
+			movement.StockBefore != w.StockBefore || movement.StockAfter != w.StockAfter ||
+			movement.ReservedBefore != w.ReservedBefore || movement.ReservedAfter != w.ReservedAfter ||
+			movement.SalesBefore != w.SalesBefore || movement.SalesAfter != w.SalesAfter {
+			t.Errorf("第 %d 条流水 = %+v，期望 %+v", i+1, movement, w)
+		}
+	}
+
+	// 没有预占记录的早期订单取消时归还库存并扣回销量
+	ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/cancel", fixturePaidOrderID), zhangsan, nil, http.StatusOK)
+	movements = ts.stockHistory(t, fixtureIPhoneID, "type=order_cancelled")
+	if len(movements) != 1 || movements[0].StockBefore != 100 || movements[0].StockAfter != 101 ||
+		movements[0].SalesBefore-movements[0].SalesAfter != 1 {
+		t.Errorf("早期订单取消的流水 = %+v", movements)
+	}
+
+	// 超时自动取消的流水没有操作人
+	ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated).decode(t, &order)
+	if _, err := transitionOrder(ts.db, order.ID, OrderEventCancel, "", 0); err != nil {
+		t.Fatal(err)
+	}
+	movements = ts.stockHistory(t, fixtureAirPodsID, fmt.Sprintf("order_id=%d&type=order_cancelled", order.ID))
+	if len(movements) != 1 || movements[0].OperatorID != nil || movements[0].ReservedAfter != 0 {
+		t.Errorf("自动取消的流水 = %+v", movements)
+	}
+}
+
+func TestStockHistory(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.login("admin")
+
+	for _, quantity := range []int{10, 20, 30} {
+		adjust := StockAdjustmentRequest{Type: StockMovementRestock, Quantity: quantity, Reason: "进货"}
+		ts.expect(http.MethodPost, fmt.Sprintf("/products/%d/stock-adjustments", fixtureCaseID), admin, adjust, http.StatusCreated)
+	}
+	adjust := StockAdjustmentRequest{Type: StockMovementAdjustment, Quantity: -5, Reason: "盘点报损"}
+	ts.expect(http.MethodPost, fmt.Sprintf("/products/%d/stock-adjustments", fixtureCaseID), admin, adjust, http.StatusCreated)
+
+	// 默认按 id 倒序分页
+	var page PageResult
+	ts.expect(http.MethodGet, fmt.Sprintf("/products/%d/stock-history?page_size=2", fixtureCaseID), admin, nil, http.StatusOK).
+		decode(t, &page)
+	if page.Total != 4 || !page.HasMore {
+		t.Errorf("分页结果 total = %d，has_more = %v", page.Total, page.HasMore)
+	}
+	items := page.Items.([]interface{})
+	if len(items) != 2 || items[0].(map[string]interface{})["type"] != StockMovementAdjustment {
+		t.Errorf("第一页 = %v", items)
+	}
+
+	if movements := ts.stockHistory(t, fixtureCaseID, "type=restock"); len(movements) != 3 {
+		t.Errorf("进货流水 %d 条，期望 3 条", len(movements))
+	}
+	if movements := ts.stockHistory(t, fixtureCaseID, "type=restock,adjustment"); len(movements) != 4 {
+		t.Errorf("进货和调整流水 %d 条，期望 4 条", len(movements))
+	}
+	if movements := ts.stockHistory(t, fixtureIPhoneID, ""); len(movements) != 0 {
+		t.Errorf("iPhone 不应该有库存流水: %+v", movements)
+	}
+
+	ts.expect(http.MethodGet, "/products/999/stock-history", admin, nil, http.StatusNotFound)
+	ts.expect(http.MethodGet, "/products/abc/stock-history", admin, nil, http.StatusBadRequest)
+	ts.expect(http.MethodGet, "/products/1/stock-history?type=gift", admin, nil, http.StatusBadRequest)
+	ts.expect(http.MethodGet, "/products/1/stock-history?sort=quantity", admin, nil, http.StatusBadRequest)
+	ts.expect(http.MethodGet, "/products/1/stock-history", ts.login("operator"), nil, http.StatusOK)
+	ts.expect(http.MethodGet, "/products/1/stock-history", ts.login("zhangsan"), nil, http.StatusForbidden)
+}
+
+func TestAdjustProductStock(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.login("admin")
+	path := fmt.Sprintf("/products/%d/stock-adjustments", fixtureAirPodsID)
+
+	var movement StockMovement
+	restock := StockAdjustmentRequest{Type: StockMovementRestock, Quantity: 50, Reason: "供应商补货"}
+	ts.expect(http.MethodPost, path, admin, restock, http.StatusCreated).decode(t, &movement)
+	if movement.ID == 0 || movement.StockBefore != 200 || movement.StockAfter != 250 ||
+		movement.OperatorID == nil || *movement.OperatorID != fixtureAdminID || movement.Reason != "供应商补货" {
+		t.Errorf("进货流水 = %+v", movement)
+	}
+
+	// 张三购买 AirPods × 2 并支付，库存 250 -> 248
+	zhangsan := ts.login("zhangsan")
+	placeOrder := func(quantity int, events ...OrderEvent) uint {
+		req := CreateOrderRequest{AddressID: 1, Items: []CreateOrderItemRequest{{ProductID: fixtureAirPodsID, Quantity: quantity}}}
+		var order Order
+		ts.expect(http.MethodPost, "/orders", zhangsan, req, http.StatusCreated).decode(t, &order)
+		for _, event := range events {
+			ts.expect(http.MethodPost, fmt.Sprintf("/orders/%d/%s", order.ID, event), zhangsan, nil, http.StatusOK)
+		}
+		return order.ID
+	}
+	paidOrderID := placeOrder(2, OrderEventPay)
+	cancelledOrderID := placeOrder(1, OrderEventCancel)
+
+	// returnStock 对指定订单退货 quantity 件
+	returnStock := func(orderID uint, quantity, status int) apiResponse {
+		t.Helper()
+		req := StockAdjustmentRequest{Type: StockMovementReturn, Quantity: quantity, OrderID: &orderID, Reason: "七天无理由退货"}
+		return ts.expect(http.MethodPost, path, admin, req, status)
+	}
+
+	// 退货必须指定包含该商品的订单
+	ts.expect(http.MethodPost, path, admin,
+		StockAdjustmentRequest{Type: StockMovementReturn, Quantity: 1, Reason: "七天无理由退货"}, http.StatusBadRequest)
+	returnStock(fixtureShippedOrderID, 1, http.StatusBadRequest)
+	returnStock(999, 1, http.StatusNotFound)
+
+	// 待支付的订单没有扣减库存，已取消的订单取消时已经归还了库存，都不能退货
+	returnStock(fixturePendingOrderID, 1, http.StatusBadRequest)
+	returnStock(cancelledOrderID, 1, http.StatusBadRequest)
+
+	// 退货数量不能超过购买数量减去已退货的数量
+	returnStock(paidOrderID, 3, http.StatusBadRequest)
+	returnStock(paidOrderID, 1, http.StatusCreated).decode(t, &movement)
+	if movement.OrderID == nil || *movement.OrderID != paidOrderID || movement.StockBefore != 248 || movement.StockAfter != 249 {
+		t.Errorf("退货流水 = %+v", movement)
+	}
+	resp := returnStock(paidOrderID, 2, http.StatusBadRequest)
+	if !strings.Contains(resp.Message, "最多还能退货 1 件") {
+		t.Errorf("message = %q", resp.Message)
+	}
+	returnStock(paidOrderID, 1, http.StatusCreated)
+	returnStock(paidOrderID, 1, http.StatusBadRequest)
+
+	// 待支付订单预占了 AirPods × 2，库存不能调整到预占数量以下
+	placeOrder(2)
+	ts.expect(http.MethodPost, path, admin, StockAdjustmentRequest{Type: StockMovementAdjustment, Quantity: -249, Reason: "盘点"},
+		http.StatusConflict)
+	ts.expect(http.MethodPost, path, admin, StockAdjustmentRequest{Type: StockMovementAdjustment, Quantity: -248, Reason: "盘点"},
+		http.StatusCreated).decode(t, &movement)
+	if movement.StockAfter != 2 || movement.ReservedAfter != 2 {
+		t.Errorf("盘点流水 = %+v", movement)
+	}
+
+	var product Product
+	ts.expect(http.MethodGet, fmt.Sprintf("/products/%d", fixtureAirPodsID), admin, nil, http.StatusOK).decode(t, &product)
+	if product.Stock != 2 || product.ReservedStock != 2 {
+		t.Errorf("调整后库存 %d，预占 %d", product.Stock, product.ReservedStock)
+	}
+
+	for _, req := range []StockAdjustmentRequest{
+		{Type: StockMovementRestock, Quantity: -1, Reason: "进货"},
+		{Type: StockMovementOrderPaid, Quantity: 1, Reason: "支付"},
+		{Type: StockMovementAdjustment, Quantity: 0, Reason: "盘点"},
+		{Type: StockMovementAdjustment, Quantity: 1},
+	} {
+		ts.expect(http.MethodPost, path, admin, req, http.StatusBadRequest)
+	}
+	ts.expect(http.MethodPost, "/products/999/stock-adjustments", admin, restock, http.StatusNotFound)
+	ts.expect(http.MethodPost, path, ts.login("operator"), restock, http.StatusForbidden)
+	ts.expect(http.MethodPost, path, zhangsan, restock, http.StatusForbidden)
+}
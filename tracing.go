@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// serviceName 默认的服务名，用于链路追踪的 service.name
+const serviceName = "DataBaseDesign"
+
+// 链路追踪导出方式
+const (
+	TracingExporterNone   = "none"   // 不导出，仍然传递 traceparent 并在日志中记录 trace_id
+	TracingExporterStdout = "stdout" // 输出到标准输出，本地没有 collector 时用于验证
+	TracingExporterOTLP   = "otlp"   // 通过 OTLP/HTTP 发送到 collector 或 Jaeger、Tempo 等后端
+)
+
+// tracingShutdownTimeout 退出时导出剩余 span 的最长等待时间
+const tracingShutdownTimeout = 5 * time.Second
+
+// tracerName GORM 插件创建 span 使用的 tracer 名称
+const tracerName = "DataBaseDesign/gorm"
+
+// setupTracing 按配置初始化全局 TracerProvider 和 W3C traceparent 传播
+// 返回的 shutdown 在退出前调用，导出尚未发送的 span
+func setupTracing(ctx context.Context, config TracingConfig, stdout io.Writer) (shutdown func(context.Context) error, err error) {
+	// 无论是否导出都解析传入的 traceparent，日志中的 trace_id 与上游服务一致
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch config.Exporter {
+	case TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(stdout), stdouttrace.WithPrettyPrint())
+	case TracingExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.Endpoint))
+	default:
+		return func(context.Context) error { return nil }, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("创建链路追踪导出器失败: %v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("创建链路追踪资源失败: %v", err)
+	}
+
+	// 上游已经决定采样的请求跟随上游，否则按比例采样
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracing 链路追踪中间件，为每个请求创建 span
+// 探针和指标接口调用频繁且没有排查价值，不创建 span
+func Tracing() gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/livez", "/readyz", "/health", "/metrics":
+			return false
+		}
+		return true
+	}))
+}
+
+// gormTracing GORM 链路追踪插件，为每条 SQL 创建 span，作为请求 span 的子 span
+// 例如 GetUserOrdersWithProducts 的三个 Preload 查询分别对应一个 span，可以看出各自的耗时
+type gormTracing struct{}
+
+// span 和执行前的 context 在 GORM Statement 中的键
+const (
+	gormSpanKey          = "tracing:span"
+	gormParentContextKey = "tracing:parent_context"
+)
+
+func (gormTracing) Name() string {
+	return "tracing"
+}
+
+func (gormTracing) Initialize(db *gorm.DB) error {
+	system := dbSystemAttribute(db.Dialector.Name())
+
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx, span := otel.Tracer(tracerName).Start(tx.Statement.Context, "gorm."+operation,
+				trace.WithSpanKind(trace.SpanKindClient))
+			tx.InstanceSet(gormParentContextKey, tx.Statement.Context)
+			tx.InstanceSet(gormSpanKey, span)
+			tx.Statement.Context = ctx
+		}
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(gormSpanKey)
+			if !ok {
+				return
+			}
+			span, ok := value.(trace.Span)
+			if !ok {
+				return
+			}
+			defer span.End()
+
+			// 恢复执行前的 context，同一个 *gorm.DB 上的下一条 SQL 仍然是请求 span 的子 span
+			if parent, ok := tx.InstanceGet(gormParentContextKey); ok {
+				tx.Statement.Context = parent.(context.Context)
+			}
+
+			// SQL 中的参数使用占位符，不记录参数值
+			attrs := []attribute.KeyValue{
+				system,
+				semconv.DBOperationName(operation),
+				semconv.DBQueryText(tx.Statement.SQL.String()),
+				attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+			}
+			if tx.Statement.Table != "" {
+				attrs = append(attrs, semconv.DBCollectionName(tx.Statement.Table))
+			}
+			span.SetAttributes(attrs...)
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+		}
+	}
+
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register("tracing:before_create", before("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", after("create")),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", before("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", after("query")),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", before("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", after("update")),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", before("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", after("delete")),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", before("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", after("row")),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", before("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", after("raw")),
+	)
+}
+
+// dbSystemAttribute 数据库类型对应的 db.system.name 属性
+func dbSystemAttribute(dialector string) attribute.KeyValue {
+	switch dialector {
+	case DriverMySQL:
+		return semconv.DBSystemNameMySQL
+	case DriverPostgres:
+		return semconv.DBSystemNamePostgreSQL
+	case DriverSQLite:
+		return semconv.DBSystemNameSQLite
+	default:
+		return semconv.DBSystemNameKey.String(dialector)
+	}
+}
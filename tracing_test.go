@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans 将全局 TracerProvider 替换为记录 span 的实现，测试结束后恢复
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestTracingSpans(t *testing.T) {
+	ts := newTestServer(t)
+	token := ts.login("zhangsan")
+	recorder := recordSpans(t)
+
+	// 上游传入的 traceparent 被沿用，请求 span 和 SQL span 属于同一条链路
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/users/1/orders/products", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d", w.Code)
+	}
+
+	var server sdktrace.ReadOnlySpan
+	tables := make(map[string]bool)
+	spans := recorder.Ended()
+	for _, span := range spans {
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Errorf("span %s 的 trace_id = %s", span.Name(), span.SpanContext().TraceID())
+		}
+		switch span.SpanKind() {
+		case trace.SpanKindServer:
+			server = span
+		case trace.SpanKindClient:
+			for _, attr := range span.Attributes() {
+				if attr.Key == semconv.DBCollectionNameKey {
+					tables[attr.Value.AsString()] = true
+				}
+			}
+		}
+	}
+	if server == nil || server.Name() != "GET /users/:id/orders/products" {
+		t.Fatalf("缺少请求 span: %v", spans)
+	}
+	// 订单、订单项、商品的查询都有各自的 span
+	for _, table := range []string{"orders", "order_items", "products"} {
+		if !tables[table] {
+			t.Errorf("缺少 %s 表的 SQL span，已记录: %v", table, tables)
+		}
+	}
+	// Preload 的查询嵌套在上一级查询的 span 下，所有 SQL span 都在请求 span 之下
+	parents := make(map[trace.SpanID]trace.SpanID)
+	for _, span := range spans {
+		parents[span.SpanContext().SpanID()] = span.Parent().SpanID()
+	}
+	for _, span := range spans {
+		if span.SpanKind() != trace.SpanKindClient {
+			continue
+		}
+		id := span.SpanContext().SpanID()
+		for id.IsValid() && id != server.SpanContext().SpanID() {
+			id = parents[id]
+		}
+		if !id.IsValid() {
+			t.Errorf("SQL span %s 不在请求 span 之下", span.Name())
+		}
+	}
+
+	// 探针不创建请求 span
+	recorder.Reset()
+	ts.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() == trace.SpanKindServer {
+			t.Errorf("/readyz 不应创建请求 span: %s", span.Name())
+		}
+	}
+}
+
+func TestTracingStdoutExporter(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var buf bytes.Buffer
+	config := defaultConfig().Tracing
+	config.Exporter = TracingExporterStdout
+	shutdown, err := setupTracing(context.Background(), config, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := otel.Tracer(tracerName).Start(context.Background(), "gorm.query")
+	span.End()
+
+	// 退出时导出尚未发送的 span
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name": "gorm.query"`, `"Value": "DataBaseDesign"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("stdout 输出中缺少 %s:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// 用户管理相关的业务错误
+var (
+	ErrInvalidRole   = errors.New("无效的角色")
+	ErrChangeOwnRole = errors.New("不能修改自己的角色")
+)
+
+// UpdateUserRoleRequest 修改用户角色请求参数
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// updateUserRole 修改用户角色
+// 管理员不能修改自己的角色，避免系统中没有管理员
+func updateUserRole(ctx context.Context, users UserRepository, actor *User, userID uint, role string) (*User, error) {
+	if !isValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actor != nil && actor.ID == userID {
+		return nil, ErrChangeOwnRole
+	}
+	return users.UpdateRole(ctx, userID, role)
+}